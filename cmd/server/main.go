@@ -1,77 +1,290 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/taufiqoo/go-chat/internal/config"
+	grpcDelivery "github.com/taufiqoo/go-chat/internal/delivery/grpc"
 	"github.com/taufiqoo/go-chat/internal/delivery/http/handler"
+	"github.com/taufiqoo/go-chat/internal/delivery/http/middleware"
 	"github.com/taufiqoo/go-chat/internal/delivery/http/router"
 	"github.com/taufiqoo/go-chat/internal/delivery/websocket"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/moderation"
 	"github.com/taufiqoo/go-chat/internal/repository/repositoryImpl"
 	"github.com/taufiqoo/go-chat/internal/service"
 	"github.com/taufiqoo/go-chat/pkg/database"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+	"github.com/taufiqoo/go-chat/pkg/logger"
+	"github.com/taufiqoo/go-chat/pkg/mailer"
+	"github.com/taufiqoo/go-chat/pkg/metrics"
+	"github.com/taufiqoo/go-chat/pkg/push"
+	"github.com/taufiqoo/go-chat/pkg/ratelimit"
 	redisClient "github.com/taufiqoo/go-chat/pkg/redis"
+	"github.com/taufiqoo/go-chat/pkg/tracing"
+
+	"google.golang.org/grpc"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	logger.Setup(&cfg)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
 
 	// Initialize database
 	db, err := database.NewMySQLConnection(&cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		fatal("Failed to connect to database", err)
 	}
 
 	// Auto migrate
 	if err := database.AutoMigrate(db); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+		fatal("Failed to migrate database", err)
 	}
 
 	redis := redisClient.NewRedisClient(&cfg)
 	if redis == nil {
-		log.Println(" Redis not available - rate limiting disabled")
+		slog.Warn("Redis not available - using in-memory rate limiting")
 	}
 
 	// Initialize repositories
 	userRepo := repositoryImpl.NewUserRepository(db)
 	messageRepo := repositoryImpl.NewMessageRepository(db)
+	userIdentityRepo := repositoryImpl.NewUserIdentityRepository(db)
+	auditLogRepo := repositoryImpl.NewAuditLogRepository(db)
+	blockRepo := repositoryImpl.NewBlockRepository(db)
+	privacyRepo := repositoryImpl.NewPrivacyRepository(db)
+	contactRepo := repositoryImpl.NewContactRepository(db)
+	moderationFlagRepo := repositoryImpl.NewModerationFlagRepository(db)
+	reportRepo := repositoryImpl.NewReportRepository(db)
+	loginAttemptRepo := repositoryImpl.NewLoginAttemptRepository(redis)
+	pendingFrameRepo := repositoryImpl.NewPendingFrameRepository(redis)
+	webhookRepo := repositoryImpl.NewWebhookRepository(db)
+	webhookDeliveryRepo := repositoryImpl.NewWebhookDeliveryRepository(db)
+	botRepo := repositoryImpl.NewBotRepository(db)
+	botTokenRepo := repositoryImpl.NewBotTokenRepository(db)
+	outboxRepo := repositoryImpl.NewOutboxRepository(db)
+	deviceTokenRepo := repositoryImpl.NewDeviceTokenRepository(db)
+	conversationMuteRepo := repositoryImpl.NewConversationMuteRepository(db)
+	digestRepo := repositoryImpl.NewDigestRepository(db)
 
-	// Initialize usecases
-	userService := service.NewUserService(userRepo, &cfg)
-	messageService := service.NewMessageService(messageRepo, userRepo)
+	mail := mailer.NewMailer(&cfg)
+	moderator := moderation.NewFromConfig(&cfg)
+	limiter := ratelimit.New(redis)
+	pushProvider := push.NewFromConfig(&cfg)
+	bus := eventbus.New(time.Duration(cfg.EventHandlerTimeout) * time.Second)
+	eventCounter := eventbus.NewCounter()
 
 	// Initialize WebSocket hub
-	hub := websocket.NewHub()
-	go hub.Run()
+	hub := websocket.NewHub(&cfg, pendingFrameRepo)
+	metrics.RegisterHub(hub)
+	if cfg.MetricsEnabled && cfg.MetricsToken == "" {
+		slog.Warn("METRICS_TOKEN not set - /metrics is served without authentication")
+	}
+
+	// Initialize usecases
+	outboxRelay := service.NewOutboxRelay(outboxRepo, bus, &cfg)
+	userService := service.NewUserService(userRepo, loginAttemptRepo, auditLogRepo, mail, outboxRelay, hub, &cfg)
+	privacyService := service.NewPrivacyService(blockRepo, privacyRepo, contactRepo, userRepo, hub, cfg.MessagingContactsOnly)
+	contactService := service.NewContactService(contactRepo, userRepo, privacyService, hub)
+	messageService := service.NewMessageService(
+		messageRepo,
+		userRepo,
+		contactRepo,
+		moderationFlagRepo,
+		privacyService,
+		moderator,
+		limiter,
+		service.MessageRateLimitsFromConfig(&cfg),
+		outboxRelay,
+	)
+	moderationService := service.NewModerationService(moderationFlagRepo, messageRepo, auditLogRepo)
+	oidcService := service.NewOIDCService(userRepo, userIdentityRepo, outboxRelay, &cfg)
+	adminService := service.NewAdminService(userRepo, messageRepo, auditLogRepo, hub, eventCounter)
+	reportService := service.NewReportService(reportRepo, messageRepo, userRepo, auditLogRepo, adminService)
+	webhookService := service.NewWebhookService(webhookRepo, webhookDeliveryRepo, auditLogRepo, &cfg)
+	botService := service.NewBotService(botRepo, botTokenRepo, userRepo, webhookRepo, auditLogRepo)
+	notificationService := service.NewNotificationService(deviceTokenRepo, conversationMuteRepo, userRepo, messageRepo, hub, pushProvider, &cfg)
+	digestService := service.NewDigestService(digestRepo, messageRepo, hub, mail, &cfg)
+
+	// Subscribe to domain events
+	fanout := websocket.NewFanout(hub, privacyService)
+	for _, eventType := range websocket.FanoutEventTypes {
+		bus.Subscribe(eventType, fanout.HandleEvent)
+	}
+	for _, eventType := range domain.WebhookEventTypes {
+		bus.Subscribe(eventType, webhookService.HandleEvent)
+	}
+	// Registered after the fan-out, so online receivers are already served.
+	bus.Subscribe(domain.EventMessageCreated, notificationService.HandleEvent)
+	bus.Subscribe(domain.EventMessageCreated, metrics.CountMessage)
+	bus.Subscribe(eventbus.All, eventCounter.Handle)
 
 	// Initialize handlers
 	userHandler := handler.NewsUserHandler(userService)
 	messageHandler := handler.NewMessageHandler(messageService)
-	wsHandler := websocket.NewHandler(hub, messageService)
-
-	// Rate limiter config
-	rateLimitConfig := router.RateLimitConfig{
-		MaxRequests: 1,
-		Window:      2 * time.Minute,
-	}
+	oidcHandler := handler.NewOIDCHandler(oidcService, cfg.OIDCStateTTL)
+	adminHandler := handler.NewAdminHandler(adminService, userService)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+	contactHandler := handler.NewContactHandler(contactService)
+	moderationHandler := handler.NewModerationHandler(moderationService)
+	reportHandler := handler.NewReportHandler(reportService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	botHandler := handler.NewBotHandler(botService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	digestHandler := handler.NewDigestHandler(digestService)
+	wsHandler := websocket.NewHandler(hub, messageService, userService, &cfg)
+	eventsHandler := websocket.NewEventsHandler(hub, userService, &cfg)
 
 	// Setup router
 	r := router.SetupRouter(
 		userHandler,
 		messageHandler,
+		oidcHandler,
+		adminHandler,
+		privacyHandler,
+		contactHandler,
+		moderationHandler,
+		reportHandler,
+		webhookHandler,
+		botHandler,
+		notificationHandler,
+		digestHandler,
 		wsHandler,
+		eventsHandler,
+		userService,
+		botService,
 		&cfg,
-		redis,
-		rateLimitConfig,
+		middleware.NewRateLimiter(limiter, &cfg),
 	)
 
 	// Start server
-	addr := fmt.Sprintf(":%s", cfg.ServerPort)
-	log.Printf("Server starting on %s", addr)
-	if err := r.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.ServerPort),
+		Handler: r,
 	}
+
+	go func() {
+		slog.Info("Server starting", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("Failed to start server", err)
+		}
+	}()
+
+	grpcServer := grpcDelivery.NewServer(grpcDelivery.NewChatServer(hub, messageService, userService), userService, &cfg)
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPCPort))
+		if err != nil {
+			fatal("Failed to listen on gRPC port", err)
+		}
+		go func() {
+			slog.Info("gRPC server starting", "addr", lis.Addr().String())
+			if err := grpcServer.Serve(lis); err != nil {
+				fatal("Failed to start gRPC server", err)
+			}
+		}()
+	}
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	webhooksDone := make(chan struct{})
+	go func() {
+		webhookService.Run(workerCtx)
+		close(webhooksDone)
+	}()
+	outboxDone := make(chan struct{})
+	go func() {
+		outboxRelay.Run(workerCtx)
+		close(outboxDone)
+	}()
+	pushDone := make(chan struct{})
+	go func() {
+		notificationService.Run(workerCtx)
+		close(pushDone)
+	}()
+	digestDone := make(chan struct{})
+	go func() {
+		digestService.Run(workerCtx)
+		close(digestDone)
+	}()
+
+	// Wait for a termination signal
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	stop()
+
+	slog.Info("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout)*time.Second)
+	defer cancel()
+
+	// Drain the hub first: SSE, long-poll and gRPC event streams only return
+	// once their subscription is closed, and the servers wait for them.
+	// Hijacked WebSocket connections are not tracked by the server at all,
+	// so the hub also waits for their handlers before the stores close.
+	if err := hub.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("WebSocket hub shutdown", "error", err)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("HTTP server shutdown", "error", err)
+	}
+	stopGRPC(shutdownCtx, grpcServer)
+
+	// Unfinished webhook deliveries and unpublished outbox events stay
+	// queued and are sent after restart.
+	stopWorkers()
+	<-webhooksDone
+	<-outboxDone
+	<-pushDone
+	<-digestDone
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Warn("Failed to flush traces", "error", err)
+	}
+	if err := database.Close(db); err != nil {
+		slog.Error("Failed to close database", "error", err)
+	}
+	if redis != nil {
+		if err := redis.Close(); err != nil {
+			slog.Error("Failed to close Redis", "error", err)
+		}
+	}
+
+	slog.Info("Server stopped")
+}
+
+// stopGRPC waits for in-flight RPCs and cancels whatever is left when ctx
+// expires.
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("gRPC server shutdown", "error", ctx.Err())
+		srv.Stop()
+	}
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -1,15 +1,19 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	ServerPort             string
+	GRPCPort               string
+	ShutdownTimeout        int
+	RequestTimeout         int
 	DBHost                 string
 	DBPort                 string
 	DBUser                 string
@@ -23,6 +27,131 @@ type Config struct {
 	RedisPort     string
 	RedisPassword string
 	RedisDB       int
+
+	OIDCProviders map[string]OIDCProviderConfig
+	OIDCStateTTL  int
+
+	AppBaseURL string
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	LoginMaxAttempts        int
+	LoginAttemptWindow      int
+	LoginLockoutDuration    int
+	LoginBackoffAfter       int
+	LoginBackoffBaseSeconds int
+	LoginBackoffMaxSeconds  int
+	LoginIPMaxAttempts      int
+
+	MessagingContactsOnly bool
+
+	ModerationProfanityWords  []string
+	ModerationProfanityFile   string
+	ModerationProfanityAction string
+	ModerationBlockedDomains  []string
+	ModerationLinkAction      string
+	ModerationSpamRepeatLimit int
+	ModerationSpamWindow      int
+	ModerationSpamAction      string
+
+	MessageRateLimit                int
+	MessageRateWindow               int
+	MessageRateBurst                int
+	MessageNewConversationRateLimit int
+	MessageNewConversationWindow    int
+	MessageNewConversationBurst     int
+
+	RateLimitEnabled  bool
+	RateLimitPolicies map[string]RateLimitPolicy
+
+	WSSendBuffer         int
+	WSSlowConsumerPolicy string
+	WSPendingQueueSize   int
+	WSPendingQueueTTL    int
+
+	WSMaxMessageSize        int
+	WSReadBufferSize        int
+	WSWriteBufferSize       int
+	WSWriteWait             int
+	WSPongWait              int
+	WSPingPeriod            int
+	WSAllowedOrigins        []string
+	WSEnableCompression     bool
+	WSMaxConnectionsPerUser int
+
+	EventHistorySize   int
+	EventHistoryTTL    int
+	SSEHeartbeat       int
+	LongPollMaxTimeout int
+
+	WebhookMaxAttempts  int
+	WebhookRetryBase    int
+	WebhookRetryMax     int
+	WebhookTimeout      int
+	WebhookPollInterval int
+
+	OutboxPollInterval int
+	OutboxLease        int
+	OutboxRetention    int
+
+	EventHandlerTimeout int
+
+	PushCollapseWindow int
+	PushShowPreview    bool
+	FCMProjectID       string
+	FCMCredentialsFile string
+	APNSKeyFile        string
+	APNSKeyID          string
+	APNSTeamID         string
+	APNSTopic          string
+	APNSSandbox        bool
+
+	DigestEnabled       bool
+	DigestIdleHours     int
+	DigestCheckInterval int
+
+	// MetricsEnabled serves /metrics. It is off by default because the
+	// endpoint is public unless MetricsToken is set.
+	MetricsEnabled bool
+	// MetricsToken, when set, must be sent as a bearer token to /metrics.
+	MetricsToken string
+
+	// LogLevel is debug, info, warn or error; LogFormat is json or text.
+	LogLevel  string
+	LogFormat string
+	// DBSlowQueryThreshold is in milliseconds; slower queries are logged as
+	// warnings. SQL statements are only logged at debug level.
+	DBSlowQueryThreshold int
+
+	// TracingExporter is none, otlp or stdout.
+	TracingExporter     string
+	TracingOTLPEndpoint string
+	TracingServiceName  string
+	TracingSampleRatio  float64
+}
+
+type OIDCProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// RateLimitPolicy is a named HTTP rate limit. KeyBy is "ip" or "user";
+// user policies fall back to the client IP on unauthenticated requests.
+type RateLimitPolicy struct {
+	Name      string
+	Algorithm string
+	Limit     int
+	Window    int
+	Burst     int
+	KeyBy     string
 }
 
 type RedisConfig struct {
@@ -35,11 +164,14 @@ type RedisConfig struct {
 func LoadConfig() Config {
 	err := godotenv.Load()
 	if err != nil {
-		log.Println("Warning: .env file not found, using environment variables")
+		slog.Warn(".env file not found, using environment variables")
 	}
 
 	return Config{
 		ServerPort:             getEnv("SERVER_PORT", "8080"),
+		GRPCPort:               getEnv("GRPC_PORT", "9090"),
+		ShutdownTimeout:        getEnvInt("SHUTDOWN_TIMEOUT", 30),
+		RequestTimeout:         getEnvInt("REQUEST_TIMEOUT", 15),
 		DBHost:                 getEnv("DB_HOST", "localhost"),
 		DBPort:                 getEnv("DB_PORT", "3306"),
 		DBUser:                 getEnv("DB_USER", "root"),
@@ -53,7 +185,173 @@ func LoadConfig() Config {
 		RedisPort:     getEnv("REDIS_PORT", "6379"),
 		RedisPassword: getEnv("REDIS_PASSWORD", ""),
 		RedisDB:       getEnvInt("REDIS_DB", 0),
+
+		OIDCProviders: loadOIDCProviders(),
+		OIDCStateTTL:  getEnvInt("OIDC_STATE_TTL", 10),
+
+		AppBaseURL: getEnv("APP_BASE_URL", "http://localhost:8080"),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "no-reply@go-chat.local"),
+
+		LoginMaxAttempts:        getEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+		LoginAttemptWindow:      getEnvInt("LOGIN_ATTEMPT_WINDOW", 15),
+		LoginLockoutDuration:    getEnvInt("LOGIN_LOCKOUT_DURATION", 30),
+		LoginBackoffAfter:       getEnvInt("LOGIN_BACKOFF_AFTER", 3),
+		LoginBackoffBaseSeconds: getEnvInt("LOGIN_BACKOFF_BASE_SECONDS", 2),
+		LoginBackoffMaxSeconds:  getEnvInt("LOGIN_BACKOFF_MAX_SECONDS", 60),
+		LoginIPMaxAttempts:      getEnvInt("LOGIN_IP_MAX_ATTEMPTS", 50),
+
+		MessagingContactsOnly: getEnvBool("MESSAGING_CONTACTS_ONLY", false),
+
+		ModerationProfanityWords:  getEnvList("MODERATION_PROFANITY_WORDS", nil),
+		ModerationProfanityFile:   getEnv("MODERATION_PROFANITY_FILE", ""),
+		ModerationProfanityAction: getEnv("MODERATION_PROFANITY_ACTION", "mask"),
+		ModerationBlockedDomains:  getEnvList("MODERATION_BLOCKED_DOMAINS", nil),
+		ModerationLinkAction:      getEnv("MODERATION_LINK_ACTION", "reject"),
+		ModerationSpamRepeatLimit: getEnvInt("MODERATION_SPAM_REPEAT_LIMIT", 3),
+		ModerationSpamWindow:      getEnvInt("MODERATION_SPAM_WINDOW", 60),
+		ModerationSpamAction:      getEnv("MODERATION_SPAM_ACTION", "reject"),
+
+		MessageRateLimit:                getEnvInt("MESSAGE_RATE_LIMIT", 30),
+		MessageRateWindow:               getEnvInt("MESSAGE_RATE_WINDOW", 60),
+		MessageRateBurst:                getEnvInt("MESSAGE_RATE_BURST", 10),
+		MessageNewConversationRateLimit: getEnvInt("MESSAGE_NEW_CONVERSATION_RATE_LIMIT", 5),
+		MessageNewConversationWindow:    getEnvInt("MESSAGE_NEW_CONVERSATION_WINDOW", 300),
+		MessageNewConversationBurst:     getEnvInt("MESSAGE_NEW_CONVERSATION_BURST", 3),
+
+		RateLimitEnabled:  getEnvBool("RATE_LIMIT_ENABLED", true),
+		RateLimitPolicies: loadRateLimitPolicies(),
+
+		WSSendBuffer:         getEnvInt("WS_SEND_BUFFER", 256),
+		WSSlowConsumerPolicy: getEnv("WS_SLOW_CONSUMER_POLICY", "disconnect"),
+		WSPendingQueueSize:   getEnvInt("WS_PENDING_QUEUE_SIZE", 500),
+		WSPendingQueueTTL:    getEnvInt("WS_PENDING_QUEUE_TTL", 1440),
+
+		WSMaxMessageSize:        getEnvInt("WS_MAX_MESSAGE_SIZE", 8192),
+		WSReadBufferSize:        getEnvInt("WS_READ_BUFFER_SIZE", 1024),
+		WSWriteBufferSize:       getEnvInt("WS_WRITE_BUFFER_SIZE", 1024),
+		WSWriteWait:             getEnvInt("WS_WRITE_WAIT", 10),
+		WSPongWait:              getEnvInt("WS_PONG_WAIT", 60),
+		WSPingPeriod:            getEnvInt("WS_PING_PERIOD", 54),
+		WSAllowedOrigins:        getEnvList("WS_ALLOWED_ORIGINS", nil),
+		WSEnableCompression:     getEnvBool("WS_ENABLE_COMPRESSION", false),
+		WSMaxConnectionsPerUser: getEnvInt("WS_MAX_CONNECTIONS_PER_USER", 5),
+
+		EventHistorySize:   getEnvInt("EVENT_HISTORY_SIZE", 100),
+		EventHistoryTTL:    getEnvInt("EVENT_HISTORY_TTL", 10),
+		SSEHeartbeat:       getEnvInt("SSE_HEARTBEAT", 15),
+		LongPollMaxTimeout: getEnvInt("LONG_POLL_MAX_TIMEOUT", 30),
+
+		WebhookMaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookRetryBase:    getEnvInt("WEBHOOK_RETRY_BASE", 30),
+		WebhookRetryMax:     getEnvInt("WEBHOOK_RETRY_MAX", 21600),
+		WebhookTimeout:      getEnvInt("WEBHOOK_TIMEOUT", 10),
+		WebhookPollInterval: getEnvInt("WEBHOOK_POLL_INTERVAL", 5),
+
+		OutboxPollInterval: getEnvInt("OUTBOX_POLL_INTERVAL", 5),
+		OutboxLease:        getEnvInt("OUTBOX_LEASE", 30),
+		OutboxRetention:    getEnvInt("OUTBOX_RETENTION", 24),
+
+		EventHandlerTimeout: getEnvInt("EVENT_HANDLER_TIMEOUT", 5),
+
+		PushCollapseWindow: getEnvInt("PUSH_COLLAPSE_WINDOW", 10),
+		PushShowPreview:    getEnvBool("PUSH_SHOW_PREVIEW", true),
+		FCMProjectID:       getEnv("FCM_PROJECT_ID", ""),
+		FCMCredentialsFile: getEnv("FCM_CREDENTIALS_FILE", ""),
+		APNSKeyFile:        getEnv("APNS_KEY_FILE", ""),
+		APNSKeyID:          getEnv("APNS_KEY_ID", ""),
+		APNSTeamID:         getEnv("APNS_TEAM_ID", ""),
+		APNSTopic:          getEnv("APNS_TOPIC", ""),
+		APNSSandbox:        getEnvBool("APNS_SANDBOX", false),
+
+		DigestEnabled:       getEnvBool("DIGEST_ENABLED", true),
+		DigestIdleHours:     getEnvInt("DIGEST_IDLE_HOURS", 24),
+		DigestCheckInterval: getEnvInt("DIGEST_CHECK_INTERVAL", 15),
+
+		MetricsEnabled: getEnvBool("METRICS_ENABLED", false),
+		MetricsToken:   getEnv("METRICS_TOKEN", ""),
+
+		LogLevel:             getEnv("LOG_LEVEL", "info"),
+		LogFormat:            getEnv("LOG_FORMAT", "json"),
+		DBSlowQueryThreshold: getEnvInt("DB_SLOW_QUERY_THRESHOLD", 200),
+
+		TracingExporter:     getEnv("TRACING_EXPORTER", "none"),
+		TracingOTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", ""),
+		TracingServiceName:  getEnv("TRACING_SERVICE_NAME", "go-chat"),
+		TracingSampleRatio:  getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+	}
+}
+
+// loadOIDCProviders reads OIDC_PROVIDERS (e.g. "google,okta") and, for each
+// name, the OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and
+// _SCOPES variables. Providers without an issuer or client ID are skipped.
+func loadOIDCProviders() map[string]OIDCProviderConfig {
+	providers := make(map[string]OIDCProviderConfig)
+
+	for _, name := range getEnvList("OIDC_PROVIDERS", nil) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+			Scopes:       getEnvList(prefix+"SCOPES", []string{"openid", "email", "profile"}),
+		}
+
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			slog.Warn("OIDC provider is missing issuer or client ID, skipping", "provider", name)
+			continue
+		}
+
+		providers[name] = provider
+	}
+
+	return providers
+}
+
+// defaultRateLimitPolicies are the policies the router applies. Each can be
+// tuned with RATE_LIMIT_<NAME>_ALGORITHM, _LIMIT, _WINDOW (seconds), _BURST
+// and _KEY. A limit of 0 disables the policy.
+var defaultRateLimitPolicies = []RateLimitPolicy{
+	{Name: "global", Algorithm: "token_bucket", Limit: 300, Window: 60, Burst: 100, KeyBy: "ip"},
+	{Name: "auth", Algorithm: "sliding_window", Limit: 10, Window: 60, KeyBy: "ip"},
+	{Name: "register", Algorithm: "sliding_window", Limit: 5, Window: 3600, KeyBy: "ip"},
+	{Name: "api", Algorithm: "token_bucket", Limit: 120, Window: 60, Burst: 40, KeyBy: "user"},
+	{Name: "reports", Algorithm: "sliding_window", Limit: 20, Window: 3600, KeyBy: "user"},
+}
+
+func loadRateLimitPolicies() map[string]RateLimitPolicy {
+	policies := make(map[string]RateLimitPolicy)
+
+	for _, policy := range defaultRateLimitPolicies {
+		prefix := "RATE_LIMIT_" + strings.ToUpper(policy.Name) + "_"
+
+		policy.Algorithm = getEnv(prefix+"ALGORITHM", policy.Algorithm)
+		policy.Limit = getEnvInt(prefix+"LIMIT", policy.Limit)
+		policy.Window = getEnvInt(prefix+"WINDOW", policy.Window)
+		policy.Burst = getEnvInt(prefix+"BURST", policy.Burst)
+		policy.KeyBy = getEnv(prefix+"KEY", policy.KeyBy)
+
+		if policy.Algorithm != "token_bucket" && policy.Algorithm != "sliding_window" {
+			slog.Warn("Unknown rate limit algorithm, using token_bucket", "policy", policy.Name, "algorithm", policy.Algorithm)
+			policy.Algorithm = "token_bucket"
+		}
+		if policy.KeyBy != "ip" && policy.KeyBy != "user" {
+			slog.Warn("Unknown rate limit key, using ip", "policy", policy.Name, "key_by", policy.KeyBy)
+			policy.KeyBy = "ip"
+		}
+
+		policies[policy.Name] = policy
 	}
+
+	return policies
 }
 
 func (c *Config) GetRedisConfig() RedisConfig {
@@ -76,10 +374,49 @@ func getEnvInt(key string, defaultValue int) int {
 	if value, ok := os.LookupEnv(key); ok {
 		intVal, err := strconv.Atoi(value)
 		if err != nil {
-			log.Printf("Invalid int for %s, using default %d", key, defaultValue)
+			slog.Warn("Invalid int, using default", "key", key, "default", defaultValue)
 			return defaultValue
 		}
 		return intVal
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		floatVal, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			slog.Warn("Invalid float, using default", "key", key, "default", defaultValue)
+			return defaultValue
+		}
+		return floatVal
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		boolVal, err := strconv.ParseBool(value)
+		if err != nil {
+			slog.Warn("Invalid bool, using default", "key", key, "default", defaultValue)
+			return defaultValue
+		}
+		return boolVal
+	}
+	return defaultValue
+}
+
+func getEnvList(key string, defaultValue []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(value) == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
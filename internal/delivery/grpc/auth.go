@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type userIDKey struct{}
+
+// userID returns the authenticated user stored by the auth interceptors.
+func userID(ctx context.Context) uint {
+	id, _ := ctx.Value(userIDKey{}).(uint)
+	return id
+}
+
+// authenticate validates the "authorization: Bearer <jwt>" metadata, the
+// same token the REST API expects in the Authorization header, rejects
+// suspended accounts and records the user's activity.
+func authenticate(ctx context.Context, jwtSecret string, userService service.UserService) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+	}
+
+	parts := strings.Split(values[0], " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization format")
+	}
+
+	id, err := utils.ValidateToken(parts[1], jwtSecret)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	user, err := userService.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "user not found")
+	}
+	if user.IsSuspended() {
+		return nil, status.Error(codes.PermissionDenied, "account suspended")
+	}
+	if user.PasswordResetRequired {
+		return nil, status.Error(codes.PermissionDenied, "password reset required")
+	}
+	userService.RecordActivity(ctx, user)
+
+	return context.WithValue(ctx, userIDKey{}, id), nil
+}
+
+func UnaryAuthInterceptor(jwtSecret string, userService service.UserService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, jwtSecret, userService)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamAuthInterceptor(jwtSecret string, userService service.UserService) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), jwtSecret, userService)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
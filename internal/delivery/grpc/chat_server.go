@@ -0,0 +1,220 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	chatv1 "github.com/taufiqoo/go-chat/api/chat/v1"
+	"github.com/taufiqoo/go-chat/internal/delivery/websocket"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ChatServer implements chatv1.ChatServiceServer on top of the same services
+// as the REST API.
+type ChatServer struct {
+	chatv1.UnimplementedChatServiceServer
+
+	hub            *websocket.Hub
+	messageService service.MessageService
+	userService    service.UserService
+}
+
+func NewChatServer(hub *websocket.Hub, messageService service.MessageService, userService service.UserService) *ChatServer {
+	return &ChatServer{
+		hub:            hub,
+		messageService: messageService,
+		userService:    userService,
+	}
+}
+
+func (s *ChatServer) SendMessage(ctx context.Context, req *chatv1.SendMessageRequest) (*chatv1.Message, error) {
+	if req.GetReceiverId() == 0 || req.GetContent() == "" {
+		return nil, status.Error(codes.InvalidArgument, "receiver_id and content are required")
+	}
+
+	message, err := s.messageService.SendMessage(ctx, userID(ctx), &domain.SendMessageRequest{
+		ReceiverID: uint(req.GetReceiverId()),
+		Content:    req.GetContent(),
+	})
+	if err != nil {
+		return nil, sendMessageStatus(ctx, err)
+	}
+
+	return toMessage(message), nil
+}
+
+// sendMessageStatus maps a SendMessage error to a gRPC status. Errors the
+// client cannot act on are logged and reported as Internal without details.
+func sendMessageStatus(ctx context.Context, err error) error {
+	var limited *service.RateLimitedError
+	var notAllowed *service.MessageNotAllowedError
+	var rejected *service.MessageRejectedError
+
+	switch {
+	case errors.As(err, &limited):
+		st, detailErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(limited.RetryAfter),
+		})
+		if detailErr != nil {
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return st.Err()
+	case errors.Is(err, service.ErrReceiverNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &notAllowed), errors.Is(err, service.ErrAccountSuspended):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.As(err, &rejected):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		slog.ErrorContext(ctx, "Failed to send message", "error", err)
+		return status.Error(codes.Internal, "failed to send message")
+	}
+}
+
+func (s *ChatServer) GetChatHistory(ctx context.Context, req *chatv1.GetChatHistoryRequest) (*chatv1.GetChatHistoryResponse, error) {
+	if req.GetUserId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = 50
+	}
+
+	messages, err := s.messageService.GetChatHistory(ctx, userID(ctx), uint(req.GetUserId()), limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve chat history")
+	}
+
+	resp := &chatv1.GetChatHistoryResponse{Messages: make([]*chatv1.Message, 0, len(messages))}
+	for i := range messages {
+		resp.Messages = append(resp.Messages, toMessage(&messages[i]))
+	}
+	return resp, nil
+}
+
+func (s *ChatServer) GetChatList(ctx context.Context, req *chatv1.GetChatListRequest) (*chatv1.GetChatListResponse, error) {
+	chats, err := s.messageService.GetChatList(ctx, userID(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve chat list")
+	}
+
+	resp := &chatv1.GetChatListResponse{Chats: make([]*chatv1.ChatListItem, 0, len(chats))}
+	for _, chat := range chats {
+		resp.Chats = append(resp.Chats, &chatv1.ChatListItem{
+			UserId:      uint64(chat.UserID),
+			Fullname:    chat.Fullname,
+			Photo:       chat.Photo,
+			Username:    chat.Username,
+			Content:     chat.Content,
+			IsRead:      chat.IsRead,
+			UnreadCount: int32(chat.UnreadCount),
+			CreatedAt:   timestamppb.New(chat.CreatedAt),
+			IsSender:    chat.IsSender,
+		})
+	}
+	return resp, nil
+}
+
+// StreamEvents follows the hub like the SSE endpoint does.
+func (s *ChatServer) StreamEvents(req *chatv1.StreamEventsRequest, stream chatv1.ChatService_StreamEventsServer) error {
+	ctx := stream.Context()
+	id := userID(ctx)
+
+	user, err := s.userService.GetUserByID(ctx, id)
+	if err != nil {
+		return status.Error(codes.NotFound, "user not found")
+	}
+	if user.IsSuspended() {
+		return status.Error(codes.PermissionDenied, "account suspended")
+	}
+	if user.PasswordResetRequired {
+		return status.Error(codes.PermissionDenied, "password reset required")
+	}
+
+	cursor := req.GetLastEventId()
+	if cursor == 0 {
+		cursor = s.hub.LatestEventID(id)
+	}
+
+	sub, err := s.hub.Subscribe(id)
+	if err != nil {
+		if errors.Is(err, websocket.ErrTooManyConnections) {
+			return status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer s.hub.Unsubscribe(sub)
+	defer func() {
+		// Authentication recorded the start of the stream, this its end.
+		if err := s.userService.UpdateLastSeen(context.WithoutCancel(ctx), id); err != nil {
+			slog.ErrorContext(ctx, "Failed to update last seen", "error", err)
+		}
+	}()
+
+	send := func() error {
+		events, complete := s.hub.EventsSince(id, cursor)
+		if !complete {
+			reset := &chatv1.Event{Reset_: true}
+			if len(events) == 0 {
+				// Resuming from the old cursor would only be reset again.
+				cursor = s.hub.LatestEventID(id)
+				reset.Id = cursor
+			}
+			if err := stream.Send(reset); err != nil {
+				return err
+			}
+		}
+		for _, event := range events {
+			if err := stream.Send(&chatv1.Event{Id: event.ID, Data: event.Data}); err != nil {
+				return err
+			}
+			cursor = event.ID
+		}
+		return nil
+	}
+
+	if err := send(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-sub.Wake():
+			if err := send(); err != nil {
+				return err
+			}
+		case <-sub.Closing():
+			_, reason := sub.CloseReason()
+			return status.Error(codes.Unavailable, reason)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func toMessage(message *domain.Message) *chatv1.Message {
+	return &chatv1.Message{
+		Id:         uint64(message.ID),
+		SenderId:   uint64(message.SenderID),
+		ReceiverId: uint64(message.ReceiverID),
+		Content:    message.Content,
+		IsRead:     message.IsRead,
+		CreatedAt:  timestamppb.New(message.CreatedAt),
+		Sender: &chatv1.UserInfo{
+			Id:       uint64(message.Sender.ID),
+			Username: message.Sender.Username,
+			IsBot:    message.Sender.IsBot,
+		},
+	}
+}
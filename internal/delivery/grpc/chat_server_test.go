@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSendMessageStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "rate limited", err: &service.RateLimitedError{RetryAfter: time.Second}, want: codes.ResourceExhausted},
+		{name: "receiver not found", err: service.ErrReceiverNotFound, want: codes.NotFound},
+		{name: "blocked", err: &service.MessageNotAllowedError{Reason: "you cannot message this user"}, want: codes.PermissionDenied},
+		{name: "suspended", err: service.ErrAccountSuspended, want: codes.PermissionDenied},
+		{name: "rejected", err: &service.MessageRejectedError{Reason: "spam"}, want: codes.InvalidArgument},
+		{name: "deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), want: codes.DeadlineExceeded},
+		{name: "database", err: errors.New("Error 1040: Too many connections"), want: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(sendMessageStatus(context.Background(), tt.err))
+			if st.Code() != tt.want {
+				t.Errorf("code = %v, want %v", st.Code(), tt.want)
+			}
+			if tt.want == codes.Internal && st.Message() == tt.err.Error() {
+				t.Error("internal error leaked to the client")
+			}
+		})
+	}
+}
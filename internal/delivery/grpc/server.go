@@ -0,0 +1,23 @@
+package grpc
+
+import (
+	"time"
+
+	chatv1 "github.com/taufiqoo/go-chat/api/chat/v1"
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/service"
+
+	"google.golang.org/grpc"
+)
+
+func NewServer(chatServer *ChatServer, userService service.UserService, cfg *config.Config) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			UnaryTimeoutInterceptor(time.Duration(cfg.RequestTimeout)*time.Second),
+			UnaryAuthInterceptor(cfg.JWTSecret, userService),
+		),
+		grpc.StreamInterceptor(StreamAuthInterceptor(cfg.JWTSecret, userService)),
+	)
+	chatv1.RegisterChatServiceServer(srv, chatServer)
+	return srv
+}
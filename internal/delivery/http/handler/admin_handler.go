@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+type AdminHandler struct {
+	adminService service.AdminService
+	userService  service.UserService
+}
+
+func NewAdminHandler(adminService service.AdminService, userService service.UserService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		userService:  userService,
+	}
+}
+
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	users, total, err := h.adminService.ListUsers(c.Request.Context(), c.Query("q"), limit, offset)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve users")
+		return
+	}
+
+	responses := make([]domain.AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, domain.AdminUserResponse{
+			ID:                    user.ID,
+			Fullname:              user.Fullname,
+			Photo:                 user.Photo,
+			Username:              user.Username,
+			Email:                 user.Email,
+			Role:                  user.Role,
+			IsBot:                 user.IsBot,
+			SuspendedAt:           user.SuspendedAt,
+			PasswordResetRequired: user.PasswordResetRequired,
+			CreatedAt:             user.CreatedAt,
+			UpdatedAt:             user.UpdatedAt,
+		})
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Users retrieved successfully", gin.H{
+		"users": responses,
+		"total": total,
+	})
+}
+
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SuspendUserRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := h.adminService.SuspendUser(c.Request.Context(), c.GetUint("userID"), userID, req.Reason, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User suspended successfully", nil)
+}
+
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.UnsuspendUser(c.Request.Context(), c.GetUint("userID"), userID, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User unsuspended successfully", nil)
+}
+
+func (h *AdminHandler) UpdateRole(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.adminService.UpdateRole(c.Request.Context(), c.GetUint("userID"), userID, req.Role, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role updated successfully", nil)
+}
+
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.ForcePasswordReset(c.Request.Context(), c.GetUint("userID"), userID, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Password reset required for user", nil)
+}
+
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.AdminUnlock(c.Request.Context(), c.GetUint("userID"), userID, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User unlocked successfully", nil)
+}
+
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	stats, err := h.adminService.GetStats(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve stats")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Stats retrieved successfully", stats)
+}
+
+func parseUserIDParam(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+		return 0, false
+	}
+	return uint(userID), true
+}
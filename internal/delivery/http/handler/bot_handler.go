@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+type BotHandler struct {
+	botService service.BotService
+}
+
+func NewBotHandler(botService service.BotService) *BotHandler {
+	return &BotHandler{botService: botService}
+}
+
+func (h *BotHandler) CreateBot(c *gin.Context) {
+	var req domain.CreateBotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bot, err := h.botService.CreateBot(c.Request.Context(), c.GetUint("userID"), &req, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Bot created successfully", bot)
+}
+
+func (h *BotHandler) ListBots(c *gin.Context) {
+	bots, err := h.botService.ListBots(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve bots")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bots retrieved successfully", bots)
+}
+
+func (h *BotHandler) GetBot(c *gin.Context) {
+	botID, ok := parseBotIDParam(c)
+	if !ok {
+		return
+	}
+
+	bot, err := h.botService.GetBot(c.Request.Context(), botID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bot retrieved successfully", bot)
+}
+
+func (h *BotHandler) UpdateBot(c *gin.Context) {
+	botID, ok := parseBotIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateBotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bot, err := h.botService.UpdateBot(c.Request.Context(), c.GetUint("userID"), botID, &req, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bot updated successfully", bot)
+}
+
+// CreateToken returns the plain token. It is only shown here.
+func (h *BotHandler) CreateToken(c *gin.Context) {
+	botID, ok := parseBotIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateBotTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, plain, err := h.botService.CreateToken(c.Request.Context(), c.GetUint("userID"), botID, &req, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Token created successfully", gin.H{
+		"token":   plain,
+		"details": token,
+	})
+}
+
+func (h *BotHandler) ListTokens(c *gin.Context) {
+	botID, ok := parseBotIDParam(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.botService.ListTokens(c.Request.Context(), botID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tokens retrieved successfully", tokens)
+}
+
+func (h *BotHandler) RevokeToken(c *gin.Context) {
+	botID, ok := parseBotIDParam(c)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("tokenId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	if err := h.botService.RevokeToken(c.Request.Context(), c.GetUint("userID"), botID, uint(tokenID), c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Token revoked successfully", nil)
+}
+
+// SetWebhook returns the signing secret. It is only shown here.
+func (h *BotHandler) SetWebhook(c *gin.Context) {
+	botID, ok := parseBotIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SetBotWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webhook, err := h.botService.SetWebhook(c.Request.Context(), c.GetUint("userID"), botID, &req, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook set successfully", gin.H{
+		"webhook": webhook,
+		"secret":  webhook.Secret,
+	})
+}
+
+func (h *BotHandler) RemoveWebhook(c *gin.Context) {
+	botID, ok := parseBotIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.botService.RemoveWebhook(c.Request.Context(), c.GetUint("userID"), botID, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook removed successfully", nil)
+}
+
+// Me returns the authenticated bot.
+func (h *BotHandler) Me(c *gin.Context) {
+	bot, err := h.botService.GetBot(c.Request.Context(), c.GetUint("botID"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	scopes, _ := c.Get("botScopes")
+	utils.SuccessResponse(c, http.StatusOK, "Bot retrieved successfully", gin.H{
+		"bot":    bot,
+		"scopes": scopes,
+	})
+}
+
+func parseBotIDParam(c *gin.Context) (uint, bool) {
+	botID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid bot ID")
+		return 0, false
+	}
+	return uint(botID), true
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+type ContactHandler struct {
+	contactService service.ContactService
+}
+
+func NewContactHandler(contactService service.ContactService) *ContactHandler {
+	return &ContactHandler{contactService: contactService}
+}
+
+func (h *ContactHandler) GetContacts(c *gin.Context) {
+	contacts, err := h.contactService.GetContacts(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve contacts")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contacts retrieved successfully", contacts)
+}
+
+func (h *ContactHandler) RemoveContact(c *gin.Context) {
+	otherUserID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.contactService.RemoveContact(c.Request.Context(), c.GetUint("userID"), otherUserID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contact removed successfully", nil)
+}
+
+func (h *ContactHandler) GetRequests(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var (
+		requests []domain.ContactRequestResponse
+		err      error
+	)
+	if c.DefaultQuery("direction", "incoming") == "outgoing" {
+		requests, err = h.contactService.GetOutgoingRequests(c.Request.Context(), userID)
+	} else {
+		requests, err = h.contactService.GetIncomingRequests(c.Request.Context(), userID)
+	}
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve contact requests")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contact requests retrieved successfully", requests)
+}
+
+func (h *ContactHandler) SendRequest(c *gin.Context) {
+	var req domain.ContactRequestInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	request, err := h.contactService.SendRequest(c.Request.Context(), c.GetUint("userID"), req.UserID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Contact request sent successfully", request)
+}
+
+func (h *ContactHandler) AcceptRequest(c *gin.Context) {
+	requestID, ok := parseRequestIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.contactService.AcceptRequest(c.Request.Context(), c.GetUint("userID"), requestID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contact request accepted", nil)
+}
+
+func (h *ContactHandler) DeclineRequest(c *gin.Context) {
+	requestID, ok := parseRequestIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.contactService.DeclineRequest(c.Request.Context(), c.GetUint("userID"), requestID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contact request declined", nil)
+}
+
+func (h *ContactHandler) CancelRequest(c *gin.Context) {
+	requestID, ok := parseRequestIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.contactService.CancelRequest(c.Request.Context(), c.GetUint("userID"), requestID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contact request cancelled", nil)
+}
+
+func parseRequestIDParam(c *gin.Context) (uint, bool) {
+	requestID, err := strconv.ParseUint(c.Param("requestId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request ID")
+		return 0, false
+	}
+	return uint(requestID), true
+}
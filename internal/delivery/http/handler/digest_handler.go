@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+type DigestHandler struct {
+	digestService service.DigestService
+}
+
+func NewDigestHandler(digestService service.DigestService) *DigestHandler {
+	return &DigestHandler{digestService: digestService}
+}
+
+func (h *DigestHandler) GetPreferences(c *gin.Context) {
+	preferences, err := h.digestService.GetPreferences(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve digest preferences")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Digest preferences retrieved successfully", preferences)
+}
+
+func (h *DigestHandler) UpdatePreferences(c *gin.Context) {
+	var req domain.UpdateDigestPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	preferences, err := h.digestService.UpdatePreferences(c.Request.Context(), c.GetUint("userID"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update digest preferences")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Digest preferences updated successfully", preferences)
+}
+
+// unsubscribePage is shown in the browser, so it is HTML rather than the
+// JSON the rest of the API returns.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Email digest</title>
+</head>
+<body>
+<p>{{.Message}}</p>
+{{if .Token}}<form method="post" action="?token={{.Token}}"><button type="submit">Unsubscribe</button></form>{{end}}
+</body>
+</html>
+`))
+
+// ConfirmUnsubscribe shows the page behind the link in digest emails. It
+// changes nothing, since mail scanners follow every link in an email.
+func (h *DigestHandler) ConfirmUnsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		renderUnsubscribePage(c, http.StatusBadRequest, "This unsubscribe link is incomplete.", "")
+		return
+	}
+
+	renderUnsubscribePage(c, http.StatusOK, "Stop receiving email digests of your unread messages?", token)
+}
+
+// Unsubscribe handles the confirmation form and one-click unsubscribe
+// requests from mail clients (RFC 8058).
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		renderUnsubscribePage(c, http.StatusBadRequest, "This unsubscribe link is incomplete.", "")
+		return
+	}
+
+	if err := h.digestService.Unsubscribe(c.Request.Context(), token); err != nil {
+		renderUnsubscribePage(c, http.StatusBadRequest, "Could not unsubscribe: "+err.Error()+".", "")
+		return
+	}
+
+	renderUnsubscribePage(c, http.StatusOK, "You will no longer receive digest emails.", "")
+}
+
+func renderUnsubscribePage(c *gin.Context, status int, message, token string) {
+	var page bytes.Buffer
+	err := unsubscribePage.Execute(&page, struct {
+		Message string
+		Token   string
+	}{message, token})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to render unsubscribe page", "error", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", page.Bytes())
+}
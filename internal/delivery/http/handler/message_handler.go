@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 
@@ -28,9 +31,25 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
-	message, err := h.messageService.SendMessage(userID, &req)
+	message, err := h.messageService.SendMessage(c.Request.Context(), userID, &req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		var limited *service.RateLimitedError
+		var notAllowed *service.MessageNotAllowedError
+		var rejected *service.MessageRejectedError
+		switch {
+		case errors.As(err, &limited):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+			utils.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, service.ErrReceiverNotFound):
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		case errors.As(err, &notAllowed), errors.Is(err, service.ErrAccountSuspended):
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+		case errors.As(err, &rejected):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		default:
+			slog.ErrorContext(c.Request.Context(), "Failed to send message", "error", err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to send message")
+		}
 		return
 	}
 
@@ -50,7 +69,7 @@ func (h *MessageHandler) GetChatHistory(c *gin.Context) {
 	limitStr := c.DefaultQuery("limit", "50")
 	limit, _ := strconv.Atoi(limitStr)
 
-	messages, err := h.messageService.GetChatHistory(userID, uint(otherUserID), limit)
+	messages, err := h.messageService.GetChatHistory(c.Request.Context(), userID, uint(otherUserID), limit)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve chat history")
 		return
@@ -67,7 +86,12 @@ func (h *MessageHandler) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	if err := h.messageService.MarkMessageAsRead(uint(messageID)); err != nil {
+	err = h.messageService.MarkMessageAsRead(c.Request.Context(), c.GetUint("userID"), uint(messageID))
+	if errors.Is(err, service.ErrMessageNotFound) {
+		utils.ErrorResponse(c, http.StatusNotFound, "Message not found")
+		return
+	}
+	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to mark message as read")
 		return
 	}
@@ -78,7 +102,7 @@ func (h *MessageHandler) MarkAsRead(c *gin.Context) {
 func (h *MessageHandler) GetUnreadCount(c *gin.Context) {
 	userID := c.GetUint("userID")
 
-	count, err := h.messageService.GetUnreadCount(userID)
+	count, err := h.messageService.GetUnreadCount(c.Request.Context(), userID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get unread count")
 		return
@@ -92,7 +116,7 @@ func (h *MessageHandler) GetUnreadCount(c *gin.Context) {
 func (h *MessageHandler) GetChatList(c *gin.Context) {
 	userID := c.GetUint("userID")
 
-	chatList, err := h.messageService.GetChatList(userID)
+	chatList, err := h.messageService.GetChatList(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get chat list",
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+type ModerationHandler struct {
+	moderationService service.ModerationService
+}
+
+func NewModerationHandler(moderationService service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService}
+}
+
+func (h *ModerationHandler) ListFlags(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	flags, total, err := h.moderationService.ListFlags(c.Request.Context(), c.DefaultQuery("status", domain.FlagStatusPending), limit, offset)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve flags")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Flags retrieved successfully", gin.H{
+		"flags": flags,
+		"total": total,
+	})
+}
+
+func (h *ModerationHandler) ReviewFlag(c *gin.Context) {
+	flagID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid flag ID")
+		return
+	}
+
+	var req domain.ReviewFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.moderationService.ReviewFlag(c.Request.Context(), c.GetUint("userID"), uint(flagID), req.Decision, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Flag reviewed successfully", nil)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	var req domain.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	device, err := h.notificationService.RegisterDevice(c.Request.Context(), c.GetUint("userID"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to register device")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Device registered successfully", device)
+}
+
+func (h *NotificationHandler) ListDevices(c *gin.Context) {
+	devices, err := h.notificationService.ListDevices(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve devices")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Devices retrieved successfully", devices)
+}
+
+func (h *NotificationHandler) UnregisterDevice(c *gin.Context) {
+	deviceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	if err := h.notificationService.UnregisterDevice(c.Request.Context(), c.GetUint("userID"), uint(deviceID)); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Device unregistered successfully", nil)
+}
+
+func (h *NotificationHandler) MuteConversation(c *gin.Context) {
+	otherUserID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional: without muted_until the conversation stays muted.
+	var req domain.MuteConversationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	mute, err := h.notificationService.MuteConversation(c.Request.Context(), c.GetUint("userID"), otherUserID, &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Conversation muted successfully", mute)
+}
+
+func (h *NotificationHandler) UnmuteConversation(c *gin.Context) {
+	otherUserID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.UnmuteConversation(c.Request.Context(), c.GetUint("userID"), otherUserID); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to unmute conversation")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Conversation unmuted successfully", nil)
+}
+
+func (h *NotificationHandler) ListMutes(c *gin.Context) {
+	mutes, err := h.notificationService.ListMutes(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve muted conversations")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Muted conversations retrieved successfully", mutes)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+const oidcStateCookie = "oidc_state"
+
+type OIDCHandler struct {
+	oidcService service.OIDCService
+	stateTTL    int
+}
+
+func NewOIDCHandler(oidcService service.OIDCService, stateTTLMinutes int) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		stateTTL:    stateTTLMinutes * 60,
+	}
+}
+
+func (h *OIDCHandler) Start(c *gin.Context) {
+	provider := c.Param("provider")
+
+	start, err := h.oidcService.StartAuth(c.Request.Context(), provider)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.setStateCookie(c, start.State, h.stateTTL)
+	c.Redirect(http.StatusFound, start.AuthURL)
+}
+
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+
+	stateCookie, err := c.Cookie(oidcStateCookie)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Missing login state")
+		return
+	}
+
+	// The state is single use, clear it whatever the outcome.
+	h.setStateCookie(c, "", -1)
+
+	user, err := h.oidcService.HandleCallback(c.Request.Context(), provider, &domain.OIDCCallbackRequest{
+		Code:             c.Query("code"),
+		State:            c.Query("state"),
+		StateCookie:      stateCookie,
+		Error:            c.Query("error"),
+		ErrorDescription: c.Query("error_description"),
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Login successful", user)
+}
+
+func (h *OIDCHandler) setStateCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, "/api/v1/auth/oidc", "", secure, true)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+type PrivacyHandler struct {
+	privacyService service.PrivacyService
+}
+
+func NewPrivacyHandler(privacyService service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{privacyService: privacyService}
+}
+
+func (h *PrivacyHandler) BlockUser(c *gin.Context) {
+	blockedID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.privacyService.BlockUser(c.Request.Context(), c.GetUint("userID"), blockedID); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User blocked successfully", nil)
+}
+
+func (h *PrivacyHandler) UnblockUser(c *gin.Context) {
+	blockedID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.privacyService.UnblockUser(c.Request.Context(), c.GetUint("userID"), blockedID); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to unblock user")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User unblocked successfully", nil)
+}
+
+func (h *PrivacyHandler) GetBlockedUsers(c *gin.Context) {
+	blocks, err := h.privacyService.GetBlockedUsers(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve blocked users")
+		return
+	}
+
+	users := make([]domain.UserInfo, 0, len(blocks))
+	for _, block := range blocks {
+		users = append(users, domain.UserInfo{
+			ID:       block.Blocked.ID,
+			Username: block.Blocked.Username,
+			IsBot:    block.Blocked.IsBot,
+		})
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Blocked users retrieved successfully", users)
+}
+
+func (h *PrivacyHandler) GetSettings(c *gin.Context) {
+	settings, err := h.privacyService.GetSettings(c.Request.Context(), c.GetUint("userID"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve privacy settings")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Privacy settings retrieved successfully", settings)
+}
+
+func (h *PrivacyHandler) UpdateSettings(c *gin.Context) {
+	var req domain.UpdatePrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	settings, err := h.privacyService.UpdateSettings(c.Request.Context(), c.GetUint("userID"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update privacy settings")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Privacy settings updated successfully", settings)
+}
+
+func (h *PrivacyHandler) GetPresence(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	presence, err := h.privacyService.GetPresence(c.Request.Context(), c.GetUint("userID"), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Presence retrieved successfully", presence)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+func (h *ReportHandler) CreateReport(c *gin.Context) {
+	var req domain.CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.reportService.CreateReport(c.Request.Context(), c.GetUint("userID"), &req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Report submitted successfully", gin.H{
+		"id":     report.ID,
+		"status": report.Status,
+	})
+}
+
+func (h *ReportHandler) ListReports(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	reports, total, err := h.reportService.ListReports(c.Request.Context(), domain.ReportFilter{
+		Status:     c.Query("status"),
+		TargetType: c.Query("target_type"),
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve reports")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reports retrieved successfully", gin.H{
+		"reports": reports,
+		"total":   total,
+	})
+}
+
+func (h *ReportHandler) GetReport(c *gin.Context) {
+	reportID, ok := parseReportIDParam(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetReport(c.Request.Context(), reportID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Report retrieved successfully", report)
+}
+
+func (h *ReportHandler) TriageReport(c *gin.Context) {
+	reportID, ok := parseReportIDParam(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.TriageReport(c.Request.Context(), c.GetUint("userID"), reportID, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Report assigned successfully", report)
+}
+
+func (h *ReportHandler) ResolveReport(c *gin.Context) {
+	reportID, ok := parseReportIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req domain.ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.reportService.ResolveReport(c.Request.Context(), c.GetUint("userID"), reportID, &req, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Report closed successfully", report)
+}
+
+func parseReportIDParam(c *gin.Context) (uint, bool) {
+	reportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid report ID")
+		return 0, false
+	}
+	return uint(reportID), true
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/taufiqoo/go-chat/internal/domain"
@@ -24,7 +27,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.Register(&req)
+	user, err := h.userService.Register(c.Request.Context(), &req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
@@ -40,8 +43,14 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.Login(&req)
+	user, err := h.userService.Login(c.Request.Context(), &req, c.ClientIP())
 	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			utils.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
 		utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
 	}
@@ -49,10 +58,40 @@ func (h *UserHandler) Login(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Login successful", user)
 }
 
+func (h *UserHandler) UnlockAccount(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unlock token required")
+		return
+	}
+
+	if err := h.userService.UnlockAccount(c.Request.Context(), token, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Account unlocked successfully", nil)
+}
+
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req domain.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.userService.ResetPassword(c.Request.Context(), &req, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Password reset successfully", nil)
+}
+
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	userID := c.GetUint("userID")
 
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
 		return
@@ -62,13 +101,14 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		ID:       user.ID,
 		Username: user.Username,
 		Email:    user.Email,
+		Role:     user.Role,
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Profile retrieved successfully", response)
 }
 
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.userService.GetAllUsers()
+	users, err := h.userService.GetAllUsers(c.Request.Context(), c.GetUint("userID"), c.Query("q"))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve users")
 		return
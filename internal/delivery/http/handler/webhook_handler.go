@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// CreateWebhook returns the signing secret. It is only shown here.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req domain.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(c.Request.Context(), c.GetUint("userID"), &req, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Webhook created successfully", gin.H{
+		"webhook": webhook,
+		"secret":  webhook.Secret,
+	})
+}
+
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.ListWebhooks(c.Request.Context())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve webhooks")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhooks retrieved successfully", webhooks)
+}
+
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhookID, ok := parseWebhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.GetWebhook(c.Request.Context(), webhookID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook retrieved successfully", webhook)
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhookID, ok := parseWebhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(c.Request.Context(), c.GetUint("userID"), webhookID, &req, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook updated successfully", webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhookID, ok := parseWebhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(c.Request.Context(), c.GetUint("userID"), webhookID, c.ClientIP()); err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook deleted successfully", nil)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhookID, ok := parseWebhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	deliveries, total, err := h.webhookService.ListDeliveries(c.Request.Context(), webhookID, domain.WebhookDeliveryFilter{
+		Status: c.Query("status"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Deliveries retrieved successfully", gin.H{
+		"deliveries": deliveries,
+		"total":      total,
+	})
+}
+
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	webhookID, deliveryID, ok := parseDeliveryParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(c.Request.Context(), webhookID, deliveryID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Delivery retrieved successfully", delivery)
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	webhookID, deliveryID, ok := parseDeliveryParams(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), c.GetUint("userID"), webhookID, deliveryID, c.ClientIP())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Delivery queued successfully", delivery)
+}
+
+func parseWebhookIDParam(c *gin.Context) (uint, bool) {
+	webhookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook ID")
+		return 0, false
+	}
+	return uint(webhookID), true
+}
+
+func parseDeliveryParams(c *gin.Context) (uint, uint, bool) {
+	webhookID, ok := parseWebhookIDParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("deliveryId"), 10, 32)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid delivery ID")
+		return 0, 0, false
+	}
+	return webhookID, uint(deliveryID), true
+}
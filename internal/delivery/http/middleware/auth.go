@@ -1,15 +1,21 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
+	"github.com/taufiqoo/go-chat/internal/service"
 	"github.com/taufiqoo/go-chat/internal/utils"
+	"github.com/taufiqoo/go-chat/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// AuthMiddleware validates the JWT and loads the user on every request, so
+// suspensions and role changes take effect immediately. It also records the
+// user's activity.
+func AuthMiddleware(jwtSecret string, userService service.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -34,7 +40,58 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		user, err := userService.GetUserByID(c.Request.Context(), userID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "User not found")
+			c.Abort()
+			return
+		}
+		if user.IsSuspended() {
+			utils.ErrorResponse(c, http.StatusForbidden, "Account suspended")
+			c.Abort()
+			return
+		}
+		if user.PasswordResetRequired {
+			utils.ErrorResponse(c, http.StatusForbidden, "Password reset required")
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", userID)
+		c.Set("user", user)
+		c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), userID))
+		userService.RecordActivity(c.Request.Context(), user)
+		c.Next()
+	}
+}
+
+// TokenFromQuery lets clients that cannot set headers, such as browser
+// WebSockets, pass their JWT as ?token=. It must run before AuthMiddleware,
+// and an Authorization header takes precedence.
+func TokenFromQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query("token"); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
+
+// StaticTokenMiddleware requires the given bearer token. An empty token lets
+// every request through.
+func StaticTokenMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		given := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or missing token")
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+	"github.com/taufiqoo/go-chat/pkg/logger"
+)
+
+// BotAuthMiddleware authenticates "Authorization: Bot <token>" requests. It
+// sets userID to the bot's user account, so handlers written for users work
+// unchanged, plus botID and botScopes.
+func BotAuthMiddleware(botService service.BotService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header required")
+			c.Abort()
+			return
+		}
+
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bot" {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization format")
+			c.Abort()
+			return
+		}
+
+		bot, token, err := botService.Authenticate(c.Request.Context(), parts[1])
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+			c.Abort()
+			return
+		}
+
+		c.Set("userID", bot.UserID)
+		c.Set("botID", bot.ID)
+		c.Set("botScopes", token.Scopes)
+		c.Request = c.Request.WithContext(logger.WithUserID(c.Request.Context(), bot.UserID))
+		c.Next()
+	}
+}
+
+// RequireBotScope must run after BotAuthMiddleware.
+func RequireBotScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, _ := c.Get("botScopes")
+		if list, ok := scopes.(domain.StringList); !ok || !list.Has(scope) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Token is missing the "+scope+" scope")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
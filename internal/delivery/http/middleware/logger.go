@@ -1,10 +1,12 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/pkg/logger"
+	"github.com/taufiqoo/go-chat/pkg/metrics"
 )
 
 func LoggerMiddleware() gin.HandlerFunc {
@@ -13,15 +15,23 @@ func LoggerMiddleware() gin.HandlerFunc {
 
 		c.Next()
 
-		endTime := time.Now()
-		latency := endTime.Sub(startTime)
+		latency := time.Since(startTime)
+		status := c.Writer.Status()
 
-		log.Printf("[%s] %s %s %d %v",
-			c.Request.Method,
-			c.Request.RequestURI,
-			c.ClientIP(),
-			c.Writer.Status(),
-			latency,
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		}
+		slog.Log(c.Request.Context(), level, "Request",
+			"method", c.Request.Method,
+			"path", logger.RedactURL(c.Request.RequestURI),
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", logger.Milliseconds(latency),
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
 		)
+
+		metrics.ObserveHTTPRequest(c.Request.Method, c.FullPath(), status, latency)
 	}
 }
@@ -1,74 +1,87 @@
 package middleware
 
 import (
-	"context"
 	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/utils"
+	"github.com/taufiqoo/go-chat/pkg/metrics"
+	"github.com/taufiqoo/go-chat/pkg/ratelimit"
 )
 
+// RateLimiter applies the named policies from config to routes.
 type RateLimiter struct {
-	redisClient *redis.Client
-	maxRequests int
-	window      time.Duration
+	limiter  ratelimit.Limiter
+	policies map[string]config.RateLimitPolicy
+	enabled  bool
 }
 
-func NewRateLimiter(redisClient *redis.Client, maxRequests int, window time.Duration) *RateLimiter {
+func NewRateLimiter(limiter ratelimit.Limiter, cfg *config.Config) *RateLimiter {
 	return &RateLimiter{
-		redisClient: redisClient,
-		maxRequests: maxRequests,
-		window:      window,
+		limiter:  limiter,
+		policies: cfg.RateLimitPolicies,
+		enabled:  cfg.RateLimitEnabled,
 	}
 }
 
-func (rl *RateLimiter) Middleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if rl.redisClient == nil {
+// Policy returns a middleware enforcing the named policy. Policies keyed by
+// user must run after AuthMiddleware.
+func (rl *RateLimiter) Policy(name string) gin.HandlerFunc {
+	policy, ok := rl.policies[name]
+	if !ok {
+		slog.Warn("Rate limit policy is not configured", "policy", name)
+	}
+
+	rule := ratelimit.Rule{
+		Algorithm: ratelimit.Algorithm(policy.Algorithm),
+		Rate:      policy.Limit,
+		Per:       time.Duration(policy.Window) * time.Second,
+		Burst:     policy.Burst,
+	}
+
+	if !rl.enabled || !rule.Enabled() {
+		return func(c *gin.Context) {
 			c.Next()
-			return
 		}
+	}
 
-		clientIP := c.ClientIP()
-		key := fmt.Sprintf("rate_limit:%s", clientIP)
-		ctx := context.Background()
-
-		count, err := rl.redisClient.Incr(ctx, key).Result()
+	return func(c *gin.Context) {
+		result, err := rl.limiter.Allow(c.Request.Context(), rl.key(name, policy.KeyBy, c), rule)
 		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Rate limiter error", "policy", name, "error", err)
 			c.Next()
 			return
 		}
 
-		if count == 1 {
-			rl.redisClient.Expire(ctx, key, rl.window)
-		}
-
-		if count > int64(rl.maxRequests) {
-			ttl, _ := rl.redisClient.TTL(ctx, key).Result()
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(result.ResetAfter).Unix(), 10))
 
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":               "Rate limit exceeded",
-				"message":             fmt.Sprintf("Anda sudah melebihi batas hit endpoint. IP: %s telah melakukan %d request dalam 1 jam terakhir.", clientIP, count),
-				"ip":                  clientIP,
-				"limit":               rl.maxRequests,
-				"window":              rl.window.String(),
-				"retry_after_seconds": int(ttl.Seconds()),
-			})
+		if !result.Allowed {
+			metrics.RateLimited(name)
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			utils.ErrorResponse(c, http.StatusTooManyRequests,
+				fmt.Sprintf("Rate limit exceeded, try again in %d seconds", retryAfter))
 			c.Abort()
 			return
 		}
 
-		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", rl.maxRequests))
-		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", rl.maxRequests-int(count)))
-		c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", time.Now().Add(rl.window).Unix()))
-
 		c.Next()
 	}
 }
 
-func (rl *RateLimiter) LimitByIP(maxRequests int, window time.Duration) gin.HandlerFunc {
-	limiter := NewRateLimiter(rl.redisClient, maxRequests, window)
-	return limiter.Middleware()
+func (rl *RateLimiter) key(name, keyBy string, c *gin.Context) string {
+	if keyBy == "user" {
+		if userID := c.GetUint("userID"); userID != 0 {
+			return fmt.Sprintf("http:%s:user:%d", name, userID)
+		}
+	}
+	return fmt.Sprintf("http:%s:ip:%s", name, c.ClientIP())
 }
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+// RequireRole must run after AuthMiddleware, which loads the user and
+// rejects suspended accounts.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("user").(*domain.User)
+		if !user.HasRole(roles...) {
+			utils.ErrorResponse(c, http.StatusForbidden, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware sets a deadline on the request context, which cancels
+// the database and Redis calls made with it. Long-lived routes, such as
+// WebSocket and event streams, are listed in exempt by their route path. A
+// zero timeout disables the deadline.
+func TimeoutMiddleware(timeout time.Duration, exempt ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(exempt))
+	for _, route := range exempt {
+		skip[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if timeout <= 0 || skip[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -3,65 +3,116 @@ package router
 import (
 	"time"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/taufiqoo/go-chat/internal/config"
 	"github.com/taufiqoo/go-chat/internal/delivery/http/handler"
 	"github.com/taufiqoo/go-chat/internal/delivery/http/middleware"
 	"github.com/taufiqoo/go-chat/internal/delivery/websocket"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 )
 
-type RateLimitConfig struct {
-	MaxRequests int
-	Window      time.Duration
-}
-
 func SetupRouter(
 	userHandler *handler.UserHandler,
 	messageHandler *handler.MessageHandler,
+	oidcHandler *handler.OIDCHandler,
+	adminHandler *handler.AdminHandler,
+	privacyHandler *handler.PrivacyHandler,
+	contactHandler *handler.ContactHandler,
+	moderationHandler *handler.ModerationHandler,
+	reportHandler *handler.ReportHandler,
+	webhookHandler *handler.WebhookHandler,
+	botHandler *handler.BotHandler,
+	notificationHandler *handler.NotificationHandler,
+	digestHandler *handler.DigestHandler,
 	wsHandler *websocket.Handler,
+	eventsHandler *websocket.EventsHandler,
+	userService service.UserService,
+	botService service.BotService,
 	cfg *config.Config,
-	redisClient *redis.Client,
-	rateLimitConfig RateLimitConfig,
+	rateLimiter *middleware.RateLimiter,
 ) *gin.Engine {
-	r := gin.Default()
+	// LoggerMiddleware replaces gin's own request log.
+	r := gin.New()
 
 	// Middleware
-	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.TracingMiddleware(cfg.TracingServiceName))
+	r.Use(middleware.RequestIDMiddleware())
 	r.Use(middleware.LoggerMiddleware())
+	r.Use(gin.Recovery())
+	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.TimeoutMiddleware(time.Duration(cfg.RequestTimeout)*time.Second,
+		"/api/v1/ws", "/api/v1/events", "/api/v1/events/poll",
+		"/api/v1/bot/ws", "/api/v1/bot/events", "/api/v1/bot/events/poll",
+	))
 
-	if redisClient != nil {
-		rateLimiter := middleware.NewRateLimiter(
-			redisClient,
-			rateLimitConfig.MaxRequests,
-			rateLimitConfig.Window,
-		)
-		r.Use(rateLimiter.Middleware())
-	}
+	r.Use(rateLimiter.Policy("global"))
 
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	if cfg.MetricsEnabled {
+		r.GET("/metrics", middleware.StaticTokenMiddleware(cfg.MetricsToken), gin.WrapH(metrics.Handler()))
+	}
+
 	// API routes
 	api := r.Group("/api/v1")
 	{
 		// Public routes
 		auth := api.Group("/auth")
+		auth.Use(rateLimiter.Policy("auth"))
 		{
-			auth.POST("/register", userHandler.Register)
+			auth.POST("/register", rateLimiter.Policy("register"), userHandler.Register)
 			auth.POST("/login", userHandler.Login)
+			auth.GET("/unlock", userHandler.UnlockAccount)
+			auth.POST("/password/reset", userHandler.ResetPassword)
+			auth.GET("/oidc/:provider/start", oidcHandler.Start)
+			auth.GET("/oidc/:provider/callback", oidcHandler.Callback)
 		}
 
+		// Digest unsubscribe links, authenticated by their signed token. Only
+		// POST unsubscribes; GET shows a confirmation page.
+		api.GET("/digest/unsubscribe", digestHandler.ConfirmUnsubscribe)
+		api.POST("/digest/unsubscribe", digestHandler.Unsubscribe)
+
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret, userService), rateLimiter.Policy("api"))
 		{
 			// User routes
 			protected.GET("/profile", userHandler.GetProfile)
 			protected.GET("/users", userHandler.GetAllUsers)
+			protected.GET("/users/:id/presence", privacyHandler.GetPresence)
+
+			// Blocking & privacy routes
+			protected.POST("/users/:id/block", privacyHandler.BlockUser)
+			protected.DELETE("/users/:id/block", privacyHandler.UnblockUser)
+			protected.GET("/blocks", privacyHandler.GetBlockedUsers)
+			protected.GET("/privacy", privacyHandler.GetSettings)
+			protected.PUT("/privacy", privacyHandler.UpdateSettings)
+
+			// Push notification routes
+			protected.POST("/devices", notificationHandler.RegisterDevice)
+			protected.GET("/devices", notificationHandler.ListDevices)
+			protected.DELETE("/devices/:id", notificationHandler.UnregisterDevice)
+			protected.PUT("/users/:id/mute", notificationHandler.MuteConversation)
+			protected.DELETE("/users/:id/mute", notificationHandler.UnmuteConversation)
+			protected.GET("/mutes", notificationHandler.ListMutes)
+			protected.GET("/digest", digestHandler.GetPreferences)
+			protected.PUT("/digest", digestHandler.UpdatePreferences)
+
+			// Contact routes
+			protected.GET("/contacts", contactHandler.GetContacts)
+			protected.DELETE("/contacts/:id", contactHandler.RemoveContact)
+			protected.GET("/contacts/requests", contactHandler.GetRequests)
+			protected.POST("/contacts/requests", contactHandler.SendRequest)
+			protected.POST("/contacts/requests/:requestId/accept", contactHandler.AcceptRequest)
+			protected.POST("/contacts/requests/:requestId/decline", contactHandler.DeclineRequest)
+			protected.DELETE("/contacts/requests/:requestId", contactHandler.CancelRequest)
 
 			// Chat routes
 			protected.POST("/messages", messageHandler.SendMessage)
@@ -69,10 +120,90 @@ func SetupRouter(
 			protected.PATCH("/messages/:messageId/read", messageHandler.MarkAsRead)
 			protected.GET("/messages/unread/count", messageHandler.GetUnreadCount)
 			protected.GET("/messages/chat-list", messageHandler.GetChatList)
+
+			// Event stream routes for clients without WebSocket
+			protected.GET("/events", eventsHandler.Stream)
+			protected.GET("/events/poll", eventsHandler.Poll)
+
+			// Report routes
+			protected.POST("/reports", rateLimiter.Policy("reports"), reportHandler.CreateReport)
+
+			// Admin routes
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireRole(domain.RoleAdmin))
+			{
+				admin.GET("/users", adminHandler.ListUsers)
+				admin.PATCH("/users/:id/suspend", adminHandler.SuspendUser)
+				admin.PATCH("/users/:id/unsuspend", adminHandler.UnsuspendUser)
+				admin.PATCH("/users/:id/role", adminHandler.UpdateRole)
+				admin.POST("/users/:id/force-password-reset", adminHandler.ForcePasswordReset)
+				admin.POST("/users/:id/unlock", adminHandler.UnlockUser)
+				admin.GET("/stats", adminHandler.GetStats)
+			}
+
+			// Webhook routes
+			webhooks := protected.Group("/webhooks")
+			webhooks.Use(middleware.RequireRole(domain.RoleAdmin))
+			{
+				webhooks.POST("", webhookHandler.CreateWebhook)
+				webhooks.GET("", webhookHandler.ListWebhooks)
+				webhooks.GET("/:id", webhookHandler.GetWebhook)
+				webhooks.PATCH("/:id", webhookHandler.UpdateWebhook)
+				webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+				webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+				webhooks.GET("/:id/deliveries/:deliveryId", webhookHandler.GetDelivery)
+				webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+			}
+
+			// Bot management routes
+			bots := protected.Group("/bots")
+			bots.Use(middleware.RequireRole(domain.RoleAdmin))
+			{
+				bots.POST("", botHandler.CreateBot)
+				bots.GET("", botHandler.ListBots)
+				bots.GET("/:id", botHandler.GetBot)
+				bots.PATCH("/:id", botHandler.UpdateBot)
+				bots.POST("/:id/tokens", botHandler.CreateToken)
+				bots.GET("/:id/tokens", botHandler.ListTokens)
+				bots.DELETE("/:id/tokens/:tokenId", botHandler.RevokeToken)
+				bots.PUT("/:id/webhook", botHandler.SetWebhook)
+				bots.DELETE("/:id/webhook", botHandler.RemoveWebhook)
+			}
+
+			// Moderation routes
+			moderation := protected.Group("/moderation")
+			moderation.Use(middleware.RequireRole(domain.RoleModerator, domain.RoleAdmin))
+			{
+				moderation.GET("/flags", moderationHandler.ListFlags)
+				moderation.PATCH("/flags/:id", moderationHandler.ReviewFlag)
+				moderation.GET("/reports", reportHandler.ListReports)
+				moderation.GET("/reports/:id", reportHandler.GetReport)
+				moderation.PATCH("/reports/:id/triage", reportHandler.TriageReport)
+				moderation.PATCH("/reports/:id/resolve", reportHandler.ResolveReport)
+			}
 		}
 
-		// WebSocket route
-		api.GET("/ws", wsHandler.HandleWebSocket)
+		// Bot API routes, authenticated with bot tokens
+		bot := api.Group("/bot")
+		bot.Use(middleware.BotAuthMiddleware(botService), rateLimiter.Policy("api"))
+		{
+			bot.GET("/me", botHandler.Me)
+			bot.POST("/messages", middleware.RequireBotScope(domain.BotScopeMessagesWrite), messageHandler.SendMessage)
+			bot.GET("/messages/:userId", middleware.RequireBotScope(domain.BotScopeMessagesRead), messageHandler.GetChatHistory)
+			bot.GET("/messages/chat-list", middleware.RequireBotScope(domain.BotScopeMessagesRead), messageHandler.GetChatList)
+			bot.GET("/events", middleware.RequireBotScope(domain.BotScopeEventsRead), eventsHandler.Stream)
+			bot.GET("/events/poll", middleware.RequireBotScope(domain.BotScopeEventsRead), eventsHandler.Poll)
+			bot.GET("/ws", middleware.RequireBotScope(domain.BotScopeEventsRead), wsHandler.HandleWebSocket)
+		}
+
+		// WebSocket route, authenticated like the protected routes but also
+		// accepting the token in the query for browsers
+		api.GET("/ws",
+			middleware.TokenFromQuery(),
+			middleware.AuthMiddleware(cfg.JWTSecret, userService),
+			rateLimiter.Policy("api"),
+			wsHandler.HandleWebSocket,
+		)
 	}
 
 	return r
@@ -1,87 +1,203 @@
 package websocket
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/taufiqoo/go-chat/internal/config"
 )
 
-const (
-	writeWait      = 10 * time.Second
-	pongWait       = 60 * time.Second
-	pingPeriod     = (pongWait * 9) / 10
-	maxMessageSize = 512
-)
+// connConfig holds the per-connection limits from config.
+type connConfig struct {
+	maxMessageSize int64
+	writeWait      time.Duration
+	pongWait       time.Duration
+	pingPeriod     time.Duration
+}
+
+func newConnConfig(cfg *config.Config) connConfig {
+	c := connConfig{
+		maxMessageSize: int64(cfg.WSMaxMessageSize),
+		writeWait:      time.Duration(cfg.WSWriteWait) * time.Second,
+		pongWait:       time.Duration(cfg.WSPongWait) * time.Second,
+		pingPeriod:     time.Duration(cfg.WSPingPeriod) * time.Second,
+	}
+
+	if c.maxMessageSize <= 0 {
+		c.maxMessageSize = 8192
+	}
+	if c.writeWait <= 0 {
+		c.writeWait = 10 * time.Second
+	}
+	if c.pongWait <= 0 {
+		c.pongWait = 60 * time.Second
+	}
+	// Pings must go out before the peer's read deadline expires.
+	if c.pingPeriod <= 0 || c.pingPeriod >= c.pongWait {
+		c.pingPeriod = c.pongWait * 9 / 10
+	}
+
+	return c
+}
 
 type Client struct {
+	// ctx carries the user and the ID of the upgrade request for logging,
+	// and is cancelled by cancel once the connection is closed.
+	ctx      context.Context
+	cancel   context.CancelFunc
 	hub      *Hub
 	conn     *websocket.Conn
 	send     chan []byte
 	userID   uint
 	messages chan []byte
+	// done is closed when writePump returns.
+	done chan struct{}
+	// readOnly rejects incoming messages, for bot tokens without the
+	// messages:write scope.
+	readOnly bool
+
+	// mu guards send against being written to after it is closed.
+	mu         sync.Mutex
+	closed     bool
+	closeFrame []byte
+}
+
+func (c *Client) user() uint {
+	return c.userID
+}
+
+func (c *Client) finished() <-chan struct{} {
+	return c.done
+}
+
+func (c *Client) abort() {
+	c.conn.Close()
+}
+
+// trySend queues a message without blocking. It returns false if the buffer
+// is full; sends to a closed client are ignored.
+func (c *Client) trySend(message []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return true
+	}
+
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// replaceOldest discards the oldest buffered message to make room.
+func (c *Client) replaceOldest(message []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	select {
+	case <-c.send:
+	default:
+	}
+	select {
+	case c.send <- message:
+	default:
+	}
+}
+
+// close closes send so writePump flushes the buffer, writes the close frame
+// and exits. Only the first call has an effect; it reports whether this call
+// closed the client.
+func (c *Client) close(closeFrame []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	c.closed = true
+	c.closeFrame = closeFrame
+	close(c.send)
+	return true
 }
 
 func (c *Client) readPump() {
 	defer func() {
-		c.hub.unregister <- c
+		c.cancel()
+		c.hub.Unregister(c)
+		// readPump is the only sender on messages.
+		close(c.messages)
 		c.conn.Close()
 	}()
 
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	cfg := c.hub.conn
+	c.conn.SetReadLimit(cfg.maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(cfg.pongWait))
 	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.conn.SetReadDeadline(time.Now().Add(cfg.pongWait))
 		return nil
 	})
 
 	for {
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("error: %v", err)
+			if errors.Is(err, websocket.ErrReadLimit) {
+				// The connection has already answered with CloseMessageTooBig.
+				slog.WarnContext(c.ctx, "Closing WebSocket connection: message too large", "limit", cfg.maxMessageSize)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				slog.WarnContext(c.ctx, "Unexpected WebSocket close", "error", err)
 			}
 			break
 		}
 
+		// The read limit applies to the compressed frame, so a deflated
+		// message is checked again once inflated.
+		if int64(len(message)) > cfg.maxMessageSize {
+			closeMsg := websocket.FormatCloseMessage(websocket.CloseMessageTooBig, "message too large")
+			c.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(cfg.writeWait))
+			slog.WarnContext(c.ctx, "Closing WebSocket connection: message too large", "limit", cfg.maxMessageSize)
+			break
+		}
+
 		c.messages <- message
 	}
 }
 
 func (c *Client) writePump() {
-	ticker := time.NewTicker(pingPeriod)
+	cfg := c.hub.conn
+	ticker := time.NewTicker(cfg.pingPeriod)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
+		close(c.done)
 	}()
 
 	for {
 		select {
 		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.SetWriteDeadline(time.Now().Add(cfg.writeWait))
 			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				c.conn.WriteMessage(websocket.CloseMessage, c.closeFrame)
 				return
 			}
 
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			// Add queued messages
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
-			}
-
-			if err := w.Close(); err != nil {
+			// Every event is its own frame so clients can parse each as JSON.
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
 				return
 			}
 
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.SetWriteDeadline(time.Now().Add(cfg.writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
@@ -0,0 +1,16 @@
+package websocket
+
+// Event is the envelope for server-pushed notifications other than chat
+// messages, e.g. {"type":"contact.request","data":{...}}.
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// EventError reports a rejected client frame to the connection that sent it.
+const EventError = "error"
+
+type ErrorData struct {
+	Message    string `json:"message"`
+	RetryAfter int    `json:"retry_after,omitempty"`
+}
@@ -0,0 +1,258 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventsHandler serves hub events over Server-Sent Events and long-polling
+// for clients that cannot keep a WebSocket open. Sending still goes through
+// POST /messages.
+type EventsHandler struct {
+	hub            *Hub
+	userService    service.UserService
+	heartbeat      time.Duration
+	maxPollTimeout time.Duration
+}
+
+func NewEventsHandler(hub *Hub, userService service.UserService, cfg *config.Config) *EventsHandler {
+	h := &EventsHandler{
+		hub:            hub,
+		userService:    userService,
+		heartbeat:      time.Duration(cfg.SSEHeartbeat) * time.Second,
+		maxPollTimeout: time.Duration(cfg.LongPollMaxTimeout) * time.Second,
+	}
+	if h.heartbeat <= 0 {
+		h.heartbeat = 15 * time.Second
+	}
+	if h.maxPollTimeout <= 0 {
+		h.maxPollTimeout = 30 * time.Second
+	}
+	return h
+}
+
+type PollResponse struct {
+	Events      []StreamEvent `json:"events"`
+	LastEventID uint64        `json:"last_event_id"`
+	// Reset is true when events were missed and the client should reload
+	// its conversations before continuing from LastEventID.
+	Reset bool `json:"reset"`
+}
+
+// Stream sends events as text/event-stream. Reconnecting clients resume
+// from the Last-Event-ID header (or the last_event_id query parameter,
+// since EventSource cannot set headers on the first request).
+func (h *EventsHandler) Stream(c *gin.Context) {
+	userID := c.GetUint("userID")
+	if !h.allowed(c, userID) {
+		return
+	}
+
+	cursor, ok := h.cursor(c, c.GetHeader("Last-Event-ID"), c.Query("last_event_id"))
+	if !ok {
+		return
+	}
+
+	stream, ok := h.subscribe(c, userID)
+	if !ok {
+		return
+	}
+	defer h.hub.Unsubscribe(stream)
+	defer updateLastSeen(c.Request.Context(), h.userService, userID)
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Stop nginx from buffering the stream.
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", 3000)
+
+	events, complete := h.hub.EventsSince(userID, cursor)
+	if !complete {
+		cursor = h.writeReset(c, userID, events, cursor)
+	}
+	cursor = writeEvents(c, events, cursor)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-stream.Wake():
+			events, complete := h.hub.EventsSince(userID, cursor)
+			if !complete {
+				cursor = h.writeReset(c, userID, events, cursor)
+			}
+			cursor = writeEvents(c, events, cursor)
+
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+
+		case <-stream.Closing():
+			code, reason := stream.CloseReason()
+			data, _ := json.Marshal(gin.H{"code": code, "reason": reason})
+			fmt.Fprintf(c.Writer, "event: close\ndata: %s\n\n", data)
+			flusher.Flush()
+			return
+
+		case <-c.Request.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeReset tells the client to resync. Without events to resume after, it
+// also moves the client's cursor to the newest event, or every reconnect
+// with the old one would be reset again.
+func (h *EventsHandler) writeReset(c *gin.Context, userID uint, events []StreamEvent, cursor uint64) uint64 {
+	if len(events) > 0 {
+		fmt.Fprint(c.Writer, "event: reset\ndata: {}\n\n")
+		return cursor
+	}
+
+	cursor = h.hub.LatestEventID(userID)
+	fmt.Fprintf(c.Writer, "event: reset\nid: %d\ndata: {}\n\n", cursor)
+	return cursor
+}
+
+func writeEvents(c *gin.Context, events []StreamEvent, cursor uint64) uint64 {
+	for _, event := range events {
+		fmt.Fprintf(c.Writer, "id: %d\ndata: %s\n\n", event.ID, event.Data)
+		cursor = event.ID
+	}
+	return cursor
+}
+
+// Poll returns the events after the since cursor, waiting up to timeout
+// seconds for new ones if there are none yet.
+func (h *EventsHandler) Poll(c *gin.Context) {
+	userID := c.GetUint("userID")
+	if !h.allowed(c, userID) {
+		return
+	}
+
+	cursor, ok := h.cursor(c, c.Query("since"), c.GetHeader("Last-Event-ID"))
+	if !ok {
+		return
+	}
+
+	timeout := h.maxPollTimeout
+	if value := c.Query("timeout"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid timeout")
+			return
+		}
+		if requested := time.Duration(seconds) * time.Second; requested < timeout {
+			timeout = requested
+		}
+	}
+
+	// Subscribe before reading the history so an event recorded in between
+	// still wakes the request.
+	stream, ok := h.subscribe(c, userID)
+	if !ok {
+		return
+	}
+	defer h.hub.Unsubscribe(stream)
+
+	events, complete := h.hub.EventsSince(userID, cursor)
+	if len(events) == 0 && complete && timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-stream.Wake():
+			events, complete = h.hub.EventsSince(userID, cursor)
+		case <-timer.C:
+		case <-stream.Closing():
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case len(events) > 0:
+		cursor = events[len(events)-1].ID
+	case !complete:
+		// Resuming from the old cursor would only be reset again.
+		cursor = h.hub.LatestEventID(userID)
+	}
+	if events == nil {
+		events = []StreamEvent{}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Events retrieved successfully", PollResponse{
+		Events:      events,
+		LastEventID: cursor,
+		Reset:       !complete,
+	})
+}
+
+func (h *EventsHandler) allowed(c *gin.Context, userID uint) bool {
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return false
+	}
+	if user.IsSuspended() {
+		utils.ErrorResponse(c, http.StatusForbidden, "Account suspended")
+		return false
+	}
+	if user.PasswordResetRequired {
+		utils.ErrorResponse(c, http.StatusForbidden, "Password reset required")
+		return false
+	}
+	return true
+}
+
+// cursor parses the first non-empty value as an event ID. Without one the
+// client only receives events from now on.
+func (h *EventsHandler) cursor(c *gin.Context, values ...string) (uint64, bool) {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid event ID")
+			return 0, false
+		}
+		return id, true
+	}
+	return h.hub.LatestEventID(c.GetUint("userID")), true
+}
+
+func (h *EventsHandler) subscribe(c *gin.Context, userID uint) (*Stream, bool) {
+	stream, err := h.hub.Subscribe(userID)
+	if err != nil {
+		status := http.StatusServiceUnavailable
+		if errors.Is(err, ErrTooManyConnections) {
+			status = http.StatusTooManyRequests
+		}
+		utils.ErrorResponse(c, status, err.Error())
+		return nil, false
+	}
+	return stream, true
+}
@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeUserService struct {
+	service.UserService
+}
+
+func (f *fakeUserService) GetUserByID(ctx context.Context, id uint) (*domain.User, error) {
+	return &domain.User{ID: id}, nil
+}
+
+func poll(t *testing.T, h *EventsHandler, since uint64) PollResponse {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/events/poll?timeout=0&since="+strconv.FormatUint(since, 10), nil)
+	c.Set("userID", uint(1))
+	h.Poll(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Poll() status = %d, body %s", w.Code, w.Body)
+	}
+	var body struct {
+		Data PollResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return body.Data
+}
+
+func TestPollResetMovesCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub, _ := newTestHub(SlowConsumerDisconnect, 16)
+	h := NewEventsHandler(hub, &fakeUserService{}, &config.Config{})
+
+	// A cursor from before the restart, for a user without a buffer.
+	resp := poll(t, h, hub.history.watermark-1)
+	if !resp.Reset {
+		t.Fatal("first poll did not reset")
+	}
+	if resp.LastEventID < hub.history.watermark {
+		t.Errorf("reset cursor = %d, still before the watermark %d", resp.LastEventID, hub.history.watermark)
+	}
+
+	if resp := poll(t, h, resp.LastEventID); resp.Reset {
+		t.Error("polling from the reset cursor reset again")
+	}
+}
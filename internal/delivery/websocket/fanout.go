@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+)
+
+// FanoutEventTypes are the domain events pushed to live connections.
+var FanoutEventTypes = []string{
+	domain.EventMessageCreated,
+	domain.EventMessageRead,
+	domain.EventBotCommand,
+}
+
+// Fanout delivers domain events from the event bus to the WebSocket, SSE,
+// long-poll and gRPC connections of the users involved, so a message reaches
+// the receiver the same way whichever API it was sent through.
+type Fanout struct {
+	hub            *Hub
+	privacyService service.PrivacyService
+}
+
+func NewFanout(hub *Hub, privacyService service.PrivacyService) *Fanout {
+	return &Fanout{
+		hub:            hub,
+		privacyService: privacyService,
+	}
+}
+
+func (f *Fanout) HandleEvent(ctx context.Context, event eventbus.Event) {
+	switch payload := event.Payload.(type) {
+	case domain.MessageCreatedEvent:
+		f.messageCreated(ctx, payload)
+
+	case domain.MessageReadEvent:
+		// The reader's other devices clear the unread badge; the sender
+		// only sees the receipt if the reader shares read receipts.
+		f.hub.NotifyUser(ctx, payload.ReceiverID, domain.EventMessageRead, payload)
+		if f.privacyService.CanSeeReadReceipts(ctx, payload.SenderID, payload.ReceiverID) {
+			f.hub.NotifyUser(ctx, payload.SenderID, domain.EventMessageRead, payload)
+		}
+
+	case domain.BotCommandEvent:
+		f.hub.NotifyUser(ctx, payload.BotUserID, domain.EventBotCommand, payload)
+	}
+}
+
+// messageCreated pushes the message as a bare frame, as chat messages always
+// were, to the receiver and back to every connection of the sender. Events
+// without a Message, relayed from outbox rows stored before it was kept, are
+// sent without the sender and receiver objects.
+func (f *Fanout) messageCreated(ctx context.Context, event domain.MessageCreatedEvent) {
+	var message interface{} = event
+	if event.Message != nil {
+		message = event.Message
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal message", "message_id", event.ID, "error", err)
+		return
+	}
+
+	f.hub.BroadcastToUser(ctx, event.ReceiverID, data)
+	if event.SenderID != event.ReceiverID {
+		f.hub.BroadcastToUser(ctx, event.SenderID, data)
+	}
+}
@@ -1,36 +1,76 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 
+	"github.com/taufiqoo/go-chat/internal/config"
 	"github.com/taufiqoo/go-chat/internal/domain"
 	"github.com/taufiqoo/go-chat/internal/service"
 	"github.com/taufiqoo/go-chat/internal/utils"
+	"github.com/taufiqoo/go-chat/pkg/logger"
+	"github.com/taufiqoo/go-chat/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
-}
-
 type Handler struct {
 	hub            *Hub
 	messageService service.MessageService
+	userService    service.UserService
+	upgrader       websocket.Upgrader
+	// frameTimeout bounds the work done for one incoming frame.
+	frameTimeout time.Duration
 }
 
-func NewHandler(hub *Hub, messageService service.MessageService) *Handler {
+func NewHandler(hub *Hub, messageService service.MessageService, userService service.UserService, cfg *config.Config) *Handler {
 	return &Handler{
 		hub:            hub,
 		messageService: messageService,
+		userService:    userService,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:    cfg.WSReadBufferSize,
+			WriteBufferSize:   cfg.WSWriteBufferSize,
+			EnableCompression: cfg.WSEnableCompression,
+			CheckOrigin:       originChecker(cfg.WSAllowedOrigins),
+		},
+		frameTimeout: time.Duration(cfg.RequestTimeout) * time.Second,
+	}
+}
+
+// originChecker allows the configured origins, or every origin if the list
+// contains "*". With no list only same-origin requests are accepted.
+// Requests without an Origin header come from non-browser clients and are
+// always allowed.
+func originChecker(allowed []string) func(r *http.Request) bool {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	origins := make(map[string]struct{}, len(allowed))
+	for _, origin := range allowed {
+		if origin == "*" {
+			return func(r *http.Request) bool { return true }
+		}
+		origins[strings.ToLower(strings.TrimRight(origin, "/"))] = struct{}{}
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		_, ok := origins[strings.ToLower(origin)]
+		return ok
 	}
 }
 
@@ -40,90 +80,145 @@ type WSMessage struct {
 	Content    string `json:"content"`
 }
 
+// HandleWebSocket upgrades a request authenticated with a user's JWT or a
+// bot token.
 func (h *Handler) HandleWebSocket(c *gin.Context) {
-	userIDStr := c.Query("user_id")
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	userID := c.GetUint("userID")
+
+	// Bot tokens are not checked for suspension by their middleware.
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+		utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+	if user.IsSuspended() {
+		utils.ErrorResponse(c, http.StatusForbidden, "Account suspended")
+		return
+	}
+	if user.PasswordResetRequired {
+		utils.ErrorResponse(c, http.StatusForbidden, "Password reset required")
 		return
 	}
 
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	ctx := logger.WithUserID(c.Request.Context(), userID)
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		log.Println(err)
+		slog.WarnContext(ctx, "WebSocket upgrade failed", "error", err)
 		return
 	}
 
+	// clientCtx is cancelled when the socket closes. Frames already read
+	// are processed regardless, see handleFrame.
+	clientCtx, cancel := context.WithCancel(ctx)
 	client := &Client{
+		ctx:      clientCtx,
+		cancel:   cancel,
 		hub:      h.hub,
 		conn:     conn,
-		send:     make(chan []byte, 256),
-		userID:   uint(userID),
+		send:     make(chan []byte, h.hub.sendBuffer),
+		userID:   userID,
 		messages: make(chan []byte, 256),
+		done:     make(chan struct{}),
+	}
+	if scopes, ok := c.Get("botScopes"); ok {
+		client.readOnly = !scopes.(domain.StringList).Has(domain.BotScopeMessagesWrite)
 	}
 
-	client.hub.register <- client
+	if err := client.hub.Register(client); err != nil {
+		code := websocket.CloseGoingAway
+		reason := shutdownReason
+		if errors.Is(err, ErrTooManyConnections) {
+			code = closeTooManyConns
+			reason = err.Error()
+		}
+		closeMsg := websocket.FormatCloseMessage(code, reason)
+		conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(h.hub.conn.writeWait))
+		conn.Close()
+		cancel()
+		return
+	}
 
+	defer h.hub.handlers.Done()
+
+	slog.InfoContext(ctx, "WebSocket connected")
 	go client.writePump()
-	go h.handleMessages(client)
+	handled := make(chan struct{})
+	go func() {
+		h.handleMessages(client)
+		close(handled)
+	}()
 	client.readPump()
+	<-handled
+	slog.InfoContext(ctx, "WebSocket disconnected")
+	updateLastSeen(ctx, h.userService, client.userID)
 }
 
-// func (h *Handler) handleMessages(client *Client) {
-// 	for {
-// 		_, msg, err := client.conn.ReadMessage()
-// 		if err != nil {
-// 			log.Println("Error reading WS message:", err)
-// 			client.hub.unregister <- client
-// 			client.conn.Close()
-// 			return
-// 		}
-
-// 		var wsMsg WSMessage
-// 		if err := json.Unmarshal(msg, &wsMsg); err != nil {
-// 			log.Println("Invalid WS message:", err)
-// 			continue
-// 		}
-
-// 		// Simpan pesan lewat service
-// 		savedMsg, err := h.messageService.SendMessage(client.userID, &domain.SendMessageRequest{
-// 			ReceiverID: wsMsg.ReceiverID,
-// 			Content:    wsMsg.Content,
-// 		})
-// 		if err != nil {
-// 			log.Println("Error saving message:", err)
-// 			continue
-// 		}
-
-// 		// Broadcast ke receiver
-// 		messageBytes, _ := json.Marshal(savedMsg)
-// 		h.hub.BroadcastToUser(wsMsg.ReceiverID, messageBytes)
-// 	}
-// }
+// updateLastSeen records when a live connection ended: authenticated
+// requests only update the last seen time when they start.
+func updateLastSeen(ctx context.Context, userService service.UserService, userID uint) {
+	if err := userService.UpdateLastSeen(context.WithoutCancel(ctx), userID); err != nil {
+		slog.ErrorContext(ctx, "Failed to update last seen", "error", err)
+	}
+}
 
 func (h *Handler) handleMessages(client *Client) {
 	for msg := range client.messages { // Baca dari channel, bukan dari conn
-		var wsMsg WSMessage
-		if err := json.Unmarshal(msg, &wsMsg); err != nil {
-			log.Println("Invalid WS message:", err)
-			continue
-		}
+		h.handleFrame(client, msg)
+	}
+}
 
-		// Simpan pesan ke database
-		savedMsg, err := h.messageService.SendMessage(client.userID, &domain.SendMessageRequest{
-			ReceiverID: wsMsg.ReceiverID,
-			Content:    wsMsg.Content,
-		})
-		if err != nil {
-			log.Println("Error saving message:", err)
-			continue
-		}
+// handleFrame processes one incoming frame in its own trace, linked to the
+// request that opened the connection, which lasts as long as the socket.
+// The frame was accepted off the wire, so it is not cancelled when the
+// socket closes behind it; frameTimeout still bounds it.
+func (h *Handler) handleFrame(client *Client, msg []byte) {
+	ctx, span := tracing.Start(context.WithoutCancel(client.ctx), "websocket.frame",
+		trace.WithNewRoot(),
+		trace.WithLinks(trace.LinkFromContext(client.ctx)),
+		trace.WithAttributes(attribute.Int("websocket.frame_size", len(msg))),
+	)
+	defer span.End()
+
+	if h.frameTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.frameTimeout)
+		defer cancel()
+	}
 
-		// Broadcast ke receiver
-		messageBytes, _ := json.Marshal(savedMsg)
-		h.hub.BroadcastToUser(wsMsg.ReceiverID, messageBytes)
+	var wsMsg WSMessage
+	if err := json.Unmarshal(msg, &wsMsg); err != nil {
+		tracing.RecordError(span, err)
+		slog.WarnContext(ctx, "Invalid WebSocket message", "error", err)
+		h.hub.SendToClient(ctx, client, EventError, ErrorData{Message: "invalid message format"})
+		return
+	}
+	span.SetAttributes(attribute.String("websocket.message_type", wsMsg.Type))
 
-		// Broadcast juga ke sender (echo back)
-		h.hub.BroadcastToUser(client.userID, messageBytes)
+	if client.readOnly {
+		h.hub.SendToClient(ctx, client, EventError, ErrorData{Message: "token is missing the " + domain.BotScopeMessagesWrite + " scope"})
+		return
 	}
+
+	// Store the message; the event bus pushes it to the receiver
+	// and echoes it to the sender.
+	_, err := h.messageService.SendMessage(ctx, client.userID, &domain.SendMessageRequest{
+		ReceiverID: wsMsg.ReceiverID,
+		Content:    wsMsg.Content,
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		h.sendError(ctx, client, err)
+	}
+}
+
+// sendError tells the sending connection why its message was not delivered.
+func (h *Handler) sendError(ctx context.Context, client *Client, err error) {
+	data := ErrorData{Message: err.Error()}
+
+	var limited *service.RateLimitedError
+	if errors.As(err, &limited) {
+		data.RetryAfter = int(math.Ceil(limited.RetryAfter.Seconds()))
+	}
+
+	h.hub.SendToClient(ctx, client, EventError, data)
 }
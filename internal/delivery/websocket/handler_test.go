@@ -0,0 +1,49 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/service"
+)
+
+type fakeMessageService struct {
+	service.MessageService
+	mu   sync.Mutex
+	sent []string
+}
+
+func (f *fakeMessageService) SendMessage(ctx context.Context, senderID uint, req *domain.SendMessageRequest) (*domain.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, req.Content)
+	return &domain.Message{SenderID: senderID, ReceiverID: req.ReceiverID, Content: req.Content}, nil
+}
+
+func TestHandleMessagesAfterClose(t *testing.T) {
+	hub, _ := newTestHub(SlowConsumerDisconnect, 16)
+	messages := &fakeMessageService{}
+	h := &Handler{hub: hub, messageService: messages}
+
+	client := newTestClient(hub, 1)
+	client.messages = make(chan []byte, 2)
+	client.messages <- []byte(`{"type":"message","receiver_id":2,"content":"first"}`)
+	client.messages <- []byte(`{"type":"message","receiver_id":2,"content":"second"}`)
+	close(client.messages)
+
+	// The socket closed with both frames still queued.
+	client.cancel()
+	h.handleMessages(client)
+
+	if len(messages.sent) != 2 {
+		t.Errorf("sent %v, want both queued frames", messages.sent)
+	}
+	if len(client.send) != 0 {
+		t.Errorf("client received %d error frames", len(client.send))
+	}
+}
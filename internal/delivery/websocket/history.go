@@ -0,0 +1,137 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const historySweepInterval = time.Minute
+
+// StreamEvent is an event as delivered over SSE and long-polling.
+type StreamEvent struct {
+	ID   uint64          `json:"id"`
+	Data json.RawMessage `json:"data"`
+}
+
+type userHistory struct {
+	events []StreamEvent
+	// evictedID is the ID of the newest event dropped from the buffer.
+	evictedID uint64
+	lastID    uint64
+	updated   time.Time
+}
+
+// eventHistory keeps the most recent events of each user so SSE and
+// long-poll clients can resume from the last event they saw.
+//
+// IDs are per user and derived from the clock in microseconds, bumped when
+// needed to stay strictly increasing. That keeps them ordered across
+// restarts, so a stale Last-Event-ID is detected as a gap rather than
+// matching unrelated events.
+type eventHistory struct {
+	mu    sync.Mutex
+	users map[uint]*userHistory
+	// watermark is the newest ID that may have been lost without a buffer
+	// to show for it: the process start, raised to the last ID of every
+	// buffer removed by the sweep. Cursors below it cannot be trusted for
+	// users without a buffer, or with one created since. It is shared by
+	// all users, so a sweep can cost an idle user a harmless resync.
+	watermark uint64
+	size      int
+	ttl       time.Duration
+	lastSweep time.Time
+}
+
+func newEventHistory(size int, ttl time.Duration) *eventHistory {
+	if size <= 0 {
+		size = 100
+	}
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	now := time.Now()
+	return &eventHistory{
+		users:     make(map[uint]*userHistory),
+		watermark: uint64(now.UnixMicro()) - 1,
+		size:      size,
+		ttl:       ttl,
+		lastSweep: now,
+	}
+}
+
+func (e *eventHistory) record(userID uint, data []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.sweep(now)
+
+	history, ok := e.users[userID]
+	if !ok {
+		history = &userHistory{evictedID: e.watermark}
+		e.users[userID] = history
+	}
+
+	id := uint64(now.UnixMicro())
+	if id <= history.lastID {
+		id = history.lastID + 1
+	}
+	history.lastID = id
+	history.updated = now
+
+	if len(history.events) >= e.size {
+		history.evictedID = history.events[0].ID
+		history.events = append(history.events[:0], history.events[1:]...)
+	}
+	history.events = append(history.events, StreamEvent{ID: id, Data: data})
+}
+
+// since returns the events after afterID. complete is false when events
+// after afterID have already been evicted, or may have been lost in a restart
+// or a sweep, and the client has to resync.
+func (e *eventHistory) since(userID uint, afterID uint64) (events []StreamEvent, complete bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	history, ok := e.users[userID]
+	if !ok {
+		return nil, afterID == 0 || afterID >= e.watermark
+	}
+
+	for _, event := range history.events {
+		if event.ID > afterID {
+			events = append(events, event)
+		}
+	}
+	return events, afterID >= history.evictedID
+}
+
+// latest returns the ID of the user's newest event, or just before the
+// current time if there is none, as a starting cursor for new streams. An
+// event recorded in the same microsecond still comes after it.
+func (e *eventHistory) latest(userID uint) uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if history, ok := e.users[userID]; ok {
+		return history.lastID
+	}
+	return uint64(time.Now().UnixMicro()) - 1
+}
+
+func (e *eventHistory) sweep(now time.Time) {
+	if now.Sub(e.lastSweep) < historySweepInterval {
+		return
+	}
+	e.lastSweep = now
+
+	for userID, history := range e.users {
+		if now.Sub(history.updated) > e.ttl {
+			if history.lastID > e.watermark {
+				e.watermark = history.lastID
+			}
+			delete(e.users, userID)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventHistorySince(t *testing.T) {
+	// expire makes the next record sweep every buffer.
+	expire := func(h *eventHistory) {
+		h.lastSweep = time.Time{}
+		for _, history := range h.users {
+			history.updated = time.Time{}
+		}
+	}
+
+	tests := []struct {
+		name string
+		// run returns the cursor the client resumes from.
+		run          func(h *eventHistory) uint64
+		wantEvents   int
+		wantComplete bool
+	}{
+		{
+			name:         "no events yet",
+			run:          func(h *eventHistory) uint64 { return h.latest(1) },
+			wantComplete: true,
+		},
+		{
+			name:         "no cursor",
+			run:          func(h *eventHistory) uint64 { return 0 },
+			wantComplete: true,
+		},
+		{
+			name: "events after cursor",
+			run: func(h *eventHistory) uint64 {
+				cursor := h.latest(1)
+				h.record(1, []byte(`{}`))
+				h.record(1, []byte(`{}`))
+				return cursor
+			},
+			wantEvents:   2,
+			wantComplete: true,
+		},
+		{
+			name: "cursor from before a restart",
+			run: func(h *eventHistory) uint64 {
+				return h.watermark - 1
+			},
+			wantComplete: false,
+		},
+		{
+			name: "no buffer, stale cursor",
+			run: func(h *eventHistory) uint64 {
+				h.record(2, []byte(`{}`))
+				return h.watermark - 1
+			},
+			wantComplete: false,
+		},
+		{
+			name: "no buffer, resuming from latest after a reset",
+			run: func(h *eventHistory) uint64 {
+				h.since(1, h.watermark-1)
+				return h.latest(1)
+			},
+			wantComplete: true,
+		},
+		{
+			name: "resuming from latest after a reset with new events",
+			run: func(h *eventHistory) uint64 {
+				h.since(1, h.watermark-1)
+				cursor := h.latest(1)
+				h.record(1, []byte(`{}`))
+				return cursor
+			},
+			wantEvents:   1,
+			wantComplete: true,
+		},
+		{
+			name: "cursor from before a restart with new events",
+			run: func(h *eventHistory) uint64 {
+				h.record(1, []byte(`{}`))
+				return h.watermark - 1
+			},
+			wantEvents:   1,
+			wantComplete: false,
+		},
+		{
+			name: "evicted from a full buffer",
+			run: func(h *eventHistory) uint64 {
+				cursor := h.latest(1)
+				for i := 0; i < 3; i++ {
+					h.record(1, []byte(`{}`))
+				}
+				return cursor
+			},
+			wantEvents:   2,
+			wantComplete: false,
+		},
+		{
+			name: "buffer swept",
+			run: func(h *eventHistory) uint64 {
+				cursor := h.latest(1)
+				h.record(1, []byte(`{}`))
+				expire(h)
+				h.record(2, []byte(`{}`))
+				return cursor
+			},
+			wantComplete: false,
+		},
+		{
+			name: "buffer swept and recreated",
+			run: func(h *eventHistory) uint64 {
+				cursor := h.latest(1)
+				h.record(1, []byte(`{}`))
+				expire(h)
+				h.record(1, []byte(`{}`))
+				return cursor
+			},
+			wantEvents:   1,
+			wantComplete: false,
+		},
+		{
+			name: "buffer swept after the client caught up",
+			run: func(h *eventHistory) uint64 {
+				h.record(1, []byte(`{}`))
+				cursor := h.latest(1)
+				expire(h)
+				h.record(2, []byte(`{}`))
+				return cursor
+			},
+			wantComplete: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newEventHistory(2, time.Minute)
+			cursor := tt.run(h)
+
+			events, complete := h.since(1, cursor)
+			if len(events) != tt.wantEvents || complete != tt.wantComplete {
+				t.Errorf("since() = %d events, complete %v; want %d, %v", len(events), complete, tt.wantEvents, tt.wantComplete)
+			}
+		})
+	}
+}
@@ -1,56 +1,438 @@
 package websocket
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+// Application-defined close codes (4000-4999).
+const (
+	closeAccountSuspended = 4003
+	closeSlowConsumer     = 4008
+	closeTooManyConns     = 4029
+)
+
+var (
+	ErrHubClosed          = errors.New("server shutting down")
+	ErrTooManyConnections = errors.New("too many connections")
+)
+
+// Slow consumer policies, applied when a client's send buffer is full.
+const (
+	// SlowConsumerDropOldest discards the oldest buffered frame to make room.
+	SlowConsumerDropOldest = "drop_oldest"
+	// SlowConsumerDisconnect closes the connection with closeSlowConsumer.
+	SlowConsumerDisconnect = "disconnect"
+	// SlowConsumerQueue stores the frame in the user's pending queue and
+	// disconnects. The queue is replayed when the user connects again.
+	SlowConsumerQueue = "queue"
+)
+
+const shutdownReason = "server shutting down, please reconnect"
+
+// subscriber is a live connection of a user: a WebSocket Client or an SSE /
+// long-poll Stream.
+type subscriber interface {
+	user() uint
+	// trySend queues a frame without blocking and reports false when the
+	// subscriber cannot keep up.
+	trySend(message []byte) bool
+	replaceOldest(message []byte)
+	// close ends the subscription; closeFrame is a WebSocket close payload.
+	close(closeFrame []byte) bool
+	// finished is closed once the transport has written everything.
+	finished() <-chan struct{}
+	abort()
+}
+
+// Hub tracks live connections indexed by user. All methods are safe for
+// concurrent use. Sends never happen while holding the lock, so a slow
+// client cannot stall the hub.
 type Hub struct {
-	clients    map[*Client]bool
-	broadcast  chan []byte
-	register   chan *Client
-	unregister chan *Client
+	mu      sync.RWMutex
+	users   map[uint]map[subscriber]struct{}
+	clients int
+	closed  bool
+
+	history *eventHistory
+
+	// handlers counts the WebSocket handlers still running, including the
+	// frames they have yet to process, so Shutdown can wait for them
+	// before the stores they use are closed.
+	handlers sync.WaitGroup
+
+	conn             connConfig
+	maxConnsPerUser  int
+	sendBuffer       int
+	policy           string
+	pending          repository.PendingFrameRepository
+	pendingQueueSize int
+	pendingQueueTTL  time.Duration
+
+	deliveredFrames atomic.Int64
+	bufferOverflows atomic.Int64
+	droppedFrames   atomic.Int64
+	queuedFrames    atomic.Int64
+	slowDisconnects atomic.Int64
 }
 
-func NewHub() *Hub {
+func NewHub(cfg *config.Config, pending repository.PendingFrameRepository) *Hub {
+	policy := cfg.WSSlowConsumerPolicy
+	switch policy {
+	case SlowConsumerDropOldest, SlowConsumerDisconnect, SlowConsumerQueue:
+	default:
+		slog.Warn("Unknown slow consumer policy", "policy", policy, "fallback", SlowConsumerDisconnect)
+		policy = SlowConsumerDisconnect
+	}
+
+	sendBuffer := cfg.WSSendBuffer
+	if sendBuffer <= 0 {
+		sendBuffer = 256
+	}
+
 	return &Hub{
-		broadcast:  make(chan []byte),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		clients:    make(map[*Client]bool),
+		users:            make(map[uint]map[subscriber]struct{}),
+		history:          newEventHistory(cfg.EventHistorySize, time.Duration(cfg.EventHistoryTTL)*time.Minute),
+		conn:             newConnConfig(cfg),
+		maxConnsPerUser:  cfg.WSMaxConnectionsPerUser,
+		sendBuffer:       sendBuffer,
+		policy:           policy,
+		pending:          pending,
+		pendingQueueSize: cfg.WSPendingQueueSize,
+		pendingQueueTTL:  time.Duration(cfg.WSPendingQueueTTL) * time.Minute,
 	}
 }
 
-func (h *Hub) Run() {
-	for {
-		select {
-		case client := <-h.register:
-			h.clients[client] = true
-
-		case client := <-h.unregister:
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-				close(client.messages)
-			}
+// Register adds the client. Frames queued for the user while a previous
+// connection was too slow are replayed first. It fails once the hub has shut
+// down or when the user already has the maximum number of connections.
+// After a successful Register the caller must call handlers.Done once it has
+// finished with the client.
+func (h *Hub) Register(client *Client) error {
+	// Pending frames go into the buffer before the client is visible to
+	// broadcasts, so they arrive ahead of newer events.
+	var replayed, rest [][]byte
+	if h.policy == SlowConsumerQueue {
+		replayed, rest = h.replayPending(client)
+	}
 
-		case message := <-h.broadcast:
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
-			}
+	h.mu.Lock()
+	err := h.addLocked(client)
+	if err == nil {
+		// Under the lock, so it cannot race with Shutdown's Wait.
+		h.handlers.Add(1)
+	}
+	h.mu.Unlock()
+
+	if err != nil {
+		h.requeue(client.ctx, client.userID, append(replayed, rest...))
+		return err
+	}
+	h.requeue(client.ctx, client.userID, rest)
+	return nil
+}
+
+func (h *Hub) add(sub subscriber) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.addLocked(sub)
+}
+
+func (h *Hub) addLocked(sub subscriber) error {
+	if h.closed {
+		return ErrHubClosed
+	}
+
+	conns, ok := h.users[sub.user()]
+	if h.maxConnsPerUser > 0 && len(conns) >= h.maxConnsPerUser {
+		return ErrTooManyConnections
+	}
+	if !ok {
+		conns = make(map[subscriber]struct{})
+		h.users[sub.user()] = conns
+	}
+	conns[sub] = struct{}{}
+	h.clients++
+
+	return nil
+}
+
+// Unregister removes the client and closes its send channel. It can be
+// called any number of times.
+func (h *Hub) Unregister(client *Client) {
+	h.remove(client)
+	client.close(nil)
+}
+
+func (h *Hub) remove(sub subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	conns := h.users[sub.user()]
+	if _, ok := conns[sub]; !ok {
+		return
+	}
+
+	delete(conns, sub)
+	h.clients--
+	if len(conns) == 0 {
+		delete(h.users, sub.user())
+	}
+}
+
+// Subscribe registers an SSE or long-poll stream for the user.
+func (h *Hub) Subscribe(userID uint) (*Stream, error) {
+	stream := newStream(userID)
+	if err := h.add(stream); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Unsubscribe removes the stream. It must be called when the transport is
+// done writing.
+func (h *Hub) Unsubscribe(stream *Stream) {
+	h.remove(stream)
+	stream.close(nil)
+	stream.finish()
+}
+
+// EventsSince returns the user's events after afterID. complete is false if
+// some of them are no longer in the history.
+func (h *Hub) EventsSince(userID uint, afterID uint64) ([]StreamEvent, bool) {
+	return h.history.since(userID, afterID)
+}
+
+// LatestEventID returns a cursor pointing after the user's newest event.
+func (h *Hub) LatestEventID(userID uint) uint64 {
+	return h.history.latest(userID)
+}
+
+// replayPending moves the user's pending frames into the client's buffer.
+// It returns the frames it replayed and those that did not fit, which the
+// caller has to requeue.
+func (h *Hub) replayPending(client *Client) (replayed, rest [][]byte) {
+	frames, err := h.pending.PopAll(client.ctx, client.userID)
+	if err != nil {
+		slog.ErrorContext(client.ctx, "Failed to load pending frames", "error", err)
+	}
+
+	for i, frame := range frames {
+		if !client.trySend(frame) {
+			h.bufferOverflows.Add(1)
+			return frames[:i], frames[i:]
+		}
+		h.deliveredFrames.Add(1)
+	}
+	return frames, nil
+}
+
+// requeue puts frames taken off the pending queue back, in order, when they
+// could not be replayed or the connection they were loaded for is rejected.
+func (h *Hub) requeue(ctx context.Context, userID uint, frames [][]byte) {
+	// The frames were already taken off the queue and must go back even if
+	// the client has gone away.
+	ctx = context.WithoutCancel(ctx)
+	for _, frame := range frames {
+		if err := h.pending.Push(ctx, userID, frame, h.pendingQueueSize, h.pendingQueueTTL); err != nil {
+			slog.ErrorContext(ctx, "Failed to requeue frame", "user_id", userID, "error", err)
+			return
+		}
+	}
+}
+
+// deliver queues the message on the subscriber, applying the slow consumer
+// policy when its buffer is full. ctx is that of the work that produced the
+// message.
+func (h *Hub) deliver(ctx context.Context, sub subscriber, message []byte) {
+	if sub.trySend(message) {
+		h.deliveredFrames.Add(1)
+		return
+	}
+	h.bufferOverflows.Add(1)
+
+	switch h.policy {
+	case SlowConsumerDropOldest:
+		sub.replaceOldest(message)
+		h.deliveredFrames.Add(1)
+		h.droppedFrames.Add(1)
+
+	case SlowConsumerQueue:
+		if err := h.pending.Push(context.WithoutCancel(ctx), sub.user(), message, h.pendingQueueSize, h.pendingQueueTTL); err != nil {
+			slog.ErrorContext(ctx, "Failed to queue frame", "user_id", sub.user(), "error", err)
+			h.droppedFrames.Add(1)
+		} else {
+			h.queuedFrames.Add(1)
 		}
+		h.disconnectSlow(sub)
+
+	default:
+		h.droppedFrames.Add(1)
+		h.disconnectSlow(sub)
+	}
+}
+
+func (h *Hub) disconnectSlow(sub subscriber) {
+	h.remove(sub)
+	if sub.close(websocket.FormatCloseMessage(closeSlowConsumer, "slow consumer, please reconnect")) {
+		h.slowDisconnects.Add(1)
+	}
+}
+
+// userSubscribers returns a snapshot of the user's connections.
+func (h *Hub) userSubscribers(userID uint) []subscriber {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	conns := h.users[userID]
+	subs := make([]subscriber, 0, len(conns))
+	for sub := range conns {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// BroadcastToUser records the message in the user's event history and
+// queues it on every connection of the user.
+func (h *Hub) BroadcastToUser(ctx context.Context, userID uint, message []byte) {
+	h.history.record(userID, message)
+
+	for _, sub := range h.userSubscribers(userID) {
+		h.deliver(ctx, sub, message)
+	}
+}
+
+// SendToClient sends a typed event to a single connection.
+func (h *Hub) SendToClient(ctx context.Context, client *Client, eventType string, payload interface{}) {
+	data, err := json.Marshal(Event{Type: eventType, Data: payload})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal event", "event_type", eventType, "error", err)
+		return
+	}
+	h.deliver(ctx, client, data)
+}
+
+// NotifyUser sends a typed event to every connection of the user.
+func (h *Hub) NotifyUser(ctx context.Context, userID uint, eventType string, payload interface{}) {
+	data, err := json.Marshal(Event{Type: eventType, Data: payload})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal event", "event_type", eventType, "error", err)
+		return
+	}
+	h.BroadcastToUser(ctx, userID, data)
+}
+
+// DisconnectUser closes every connection of the user with a close frame
+// carrying the given reason.
+func (h *Hub) DisconnectUser(userID uint, reason string) {
+	closeMsg := websocket.FormatCloseMessage(closeAccountSuspended, reason)
+	for _, sub := range h.userSubscribers(userID) {
+		h.remove(sub)
+		sub.close(closeMsg)
+	}
+}
+
+func (h *Hub) ConnectedClients() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.clients
+}
+
+func (h *Hub) ConnectedUsers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.users)
+}
+
+// ConnectionsPerUser returns the number of connections of each connected
+// user, in no particular order.
+func (h *Hub) ConnectionsPerUser() []int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	counts := make([]int, 0, len(h.users))
+	for _, conns := range h.users {
+		counts = append(counts, len(conns))
 	}
+	return counts
 }
 
-func (h *Hub) BroadcastToUser(userID uint, message []byte) {
-	for client := range h.clients {
-		if client.userID == userID {
-			select {
-			case client.send <- message:
-			default:
-				close(client.send)
-				delete(h.clients, client)
+func (h *Hub) DeliveryStats() domain.DeliveryStats {
+	return domain.DeliveryStats{
+		SlowConsumerPolicy:      h.policy,
+		DeliveredFrames:         h.deliveredFrames.Load(),
+		BufferOverflows:         h.bufferOverflows.Load(),
+		DroppedFrames:           h.droppedFrames.Load(),
+		QueuedFrames:            h.queuedFrames.Load(),
+		SlowConsumerDisconnects: h.slowDisconnects.Load(),
+	}
+}
+
+func (h *Hub) IsOnline(userID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.users[userID]) > 0
+}
+
+// Shutdown stops accepting clients and closes every connection with a going
+// away close frame once its pending messages are written. It then waits for
+// the WebSocket handlers to process the frames they have already read.
+// Connections that have not drained when ctx expires are closed immediately.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+
+	subs := make([]subscriber, 0, h.clients)
+	for _, conns := range h.users {
+		for sub := range conns {
+			subs = append(subs, sub)
+		}
+	}
+	h.users = make(map[uint]map[subscriber]struct{})
+	h.clients = 0
+	h.mu.Unlock()
+
+	// Closing send lets writePump flush what is still buffered and then
+	// write the close frame.
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, shutdownReason)
+	for _, sub := range subs {
+		sub.close(closeMsg)
+	}
+
+	for _, sub := range subs {
+		select {
+		case <-sub.finished():
+		case <-ctx.Done():
+			for _, s := range subs {
+				s.abort()
 			}
+			return ctx.Err()
 		}
 	}
+
+	handled := make(chan struct{})
+	go func() {
+		h.handlers.Wait()
+		close(handled)
+	}()
+	select {
+	case <-handled:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
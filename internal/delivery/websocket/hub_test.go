@@ -0,0 +1,415 @@
+package websocket
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+type fakePendingFrames struct {
+	repository.PendingFrameRepository
+	mu     sync.Mutex
+	frames map[uint][][]byte
+}
+
+func (f *fakePendingFrames) Push(ctx context.Context, userID uint, frame []byte, maxFrames int, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.frames == nil {
+		f.frames = make(map[uint][][]byte)
+	}
+	f.frames[userID] = append(f.frames[userID], frame)
+	return nil
+}
+
+func (f *fakePendingFrames) PopAll(ctx context.Context, userID uint) ([][]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	frames := f.frames[userID]
+	delete(f.frames, userID)
+	return frames, nil
+}
+
+func newTestHub(policy string, sendBuffer int) (*Hub, *fakePendingFrames) {
+	pending := &fakePendingFrames{}
+	hub := NewHub(&config.Config{
+		WSSendBuffer:         sendBuffer,
+		WSSlowConsumerPolicy: policy,
+		WSPendingQueueSize:   100,
+		WSPendingQueueTTL:    1,
+		EventHistorySize:     10,
+		EventHistoryTTL:      1,
+	}, pending)
+	return hub, pending
+}
+
+// newTestClient returns a client without a connection. Its frames stay in
+// send until the test reads them or serves it.
+func newTestClient(hub *Hub, userID uint) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Client{
+		ctx:      ctx,
+		cancel:   cancel,
+		hub:      hub,
+		send:     make(chan []byte, hub.sendBuffer),
+		userID:   userID,
+		messages: make(chan []byte),
+		done:     make(chan struct{}),
+	}
+}
+
+// serve registers the client and stands in for HandleWebSocket: it consumes
+// frames until the client is closed, then marks it finished.
+func serve(client *Client) error {
+	if err := client.hub.Register(client); err != nil {
+		return err
+	}
+	go func() {
+		defer client.hub.handlers.Done()
+		for range client.send {
+		}
+		close(client.done)
+	}()
+	return nil
+}
+
+func closeCode(client *Client) int {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.closeFrame) < 2 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(client.closeFrame))
+}
+
+func isClosed(client *Client) bool {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return client.closed
+}
+
+func TestHubConcurrentRegisterUnregister(t *testing.T) {
+	hub, _ := newTestHub(SlowConsumerDisconnect, 16)
+
+	const users, perUser = 5, 20
+	var wg sync.WaitGroup
+	for u := uint(1); u <= users; u++ {
+		for i := 0; i < perUser; i++ {
+			wg.Add(1)
+			go func(userID uint) {
+				defer wg.Done()
+				client := newTestClient(hub, userID)
+				if err := serve(client); err != nil {
+					t.Errorf("Register() error = %v", err)
+					return
+				}
+				hub.NotifyUser(context.Background(), userID, "test", i)
+				hub.Unregister(client)
+				hub.Unregister(client)
+			}(u)
+		}
+
+		wg.Add(1)
+		go func(userID uint) {
+			defer wg.Done()
+			hub.DisconnectUser(userID, "account suspended")
+		}(u)
+	}
+	wg.Wait()
+
+	if got := hub.ConnectedClients(); got != 0 {
+		t.Errorf("ConnectedClients() = %d, want 0", got)
+	}
+	if got := hub.ConnectedUsers(); got != 0 {
+		t.Errorf("ConnectedUsers() = %d, want 0", got)
+	}
+}
+
+func TestHubDisconnectUser(t *testing.T) {
+	hub, _ := newTestHub(SlowConsumerDisconnect, 16)
+
+	var clients []*Client
+	for i := 0; i < 3; i++ {
+		client := newTestClient(hub, 1)
+		if err := serve(client); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		clients = append(clients, client)
+	}
+	other := newTestClient(hub, 2)
+	if err := serve(other); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hub.DisconnectUser(1, "account suspended")
+		}()
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		if code := closeCode(client); code != closeAccountSuspended {
+			t.Errorf("close code = %d, want %d", code, closeAccountSuspended)
+		}
+	}
+	if hub.IsOnline(1) {
+		t.Error("user 1 still online")
+	}
+	if !hub.IsOnline(2) || isClosed(other) {
+		t.Error("user 2 was disconnected")
+	}
+}
+
+func TestHubShutdownRacesRegister(t *testing.T) {
+	hub, _ := newTestHub(SlowConsumerDisconnect, 16)
+
+	const n = 50
+	clients := make([]*Client, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range clients {
+		clients[i] = newTestClient(hub, uint(i%5+1))
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = serve(clients[i])
+			hub.BroadcastToUser(context.Background(), clients[i].userID, []byte(`{}`))
+		}(i)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := hub.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	wg.Wait()
+
+	for i, client := range clients {
+		switch {
+		case errors.Is(errs[i], ErrHubClosed):
+			// Rejected after shutdown; the handler closes it.
+			hub.Unregister(client)
+		case errs[i] != nil:
+			t.Errorf("Register() error = %v", errs[i])
+		case closeCode(client) != websocket.CloseGoingAway:
+			t.Errorf("client %d close code = %d, want going away", i, closeCode(client))
+		}
+	}
+
+	if err := hub.Register(newTestClient(hub, 1)); !errors.Is(err, ErrHubClosed) {
+		t.Errorf("Register() after Shutdown error = %v, want %v", err, ErrHubClosed)
+	}
+	if err := hub.Shutdown(ctx); err != nil {
+		t.Errorf("second Shutdown() error = %v", err)
+	}
+}
+
+func TestHubShutdownWaitsForHandlers(t *testing.T) {
+	hub, _ := newTestHub(SlowConsumerDisconnect, 16)
+
+	client := newTestClient(hub, 1)
+	if err := hub.Register(client); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	var handled atomic.Bool
+	go func() {
+		defer hub.handlers.Done()
+		for range client.send {
+		}
+		close(client.done)
+		// Frames read before the socket closed are still being processed.
+		time.Sleep(20 * time.Millisecond)
+		handled.Store(true)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := hub.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !handled.Load() {
+		t.Error("Shutdown() returned before the handler finished")
+	}
+}
+
+func TestHubShutdownTimesOut(t *testing.T) {
+	hub, _ := newTestHub(SlowConsumerDisconnect, 16)
+
+	// A stream that is never finished by its transport.
+	if _, err := hub.Subscribe(1); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := hub.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestHubSlowConsumerPolicies(t *testing.T) {
+	frame := func(i int) []byte { return []byte(fmt.Sprintf(`{"n":%d}`, i)) }
+
+	tests := []struct {
+		policy        string
+		wantConnected bool
+		wantBuffered  []string
+		wantQueued    int
+		wantDropped   int64
+	}{
+		{
+			policy:        SlowConsumerDropOldest,
+			wantConnected: true,
+			wantBuffered:  []string{`{"n":2}`},
+			wantDropped:   2,
+		},
+		{
+			policy:       SlowConsumerDisconnect,
+			wantBuffered: []string{`{"n":0}`},
+			wantDropped:  1,
+		},
+		{
+			policy:       SlowConsumerQueue,
+			wantBuffered: []string{`{"n":0}`},
+			wantQueued:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			hub, pending := newTestHub(tt.policy, 1)
+			client := newTestClient(hub, 1)
+			if err := hub.Register(client); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+
+			for i := 0; i < 3; i++ {
+				hub.BroadcastToUser(context.Background(), 1, frame(i))
+			}
+
+			if online := hub.IsOnline(1); online != tt.wantConnected {
+				t.Errorf("IsOnline() = %v, want %v", online, tt.wantConnected)
+			}
+			if !tt.wantConnected && closeCode(client) != closeSlowConsumer {
+				t.Errorf("close code = %d, want %d", closeCode(client), closeSlowConsumer)
+			}
+
+			if tt.wantConnected {
+				hub.Unregister(client)
+			}
+			var buffered []string
+			for message := range client.send {
+				buffered = append(buffered, string(message))
+			}
+			if fmt.Sprint(buffered) != fmt.Sprint(tt.wantBuffered) {
+				t.Errorf("buffered = %v, want %v", buffered, tt.wantBuffered)
+			}
+
+			stats := hub.DeliveryStats()
+			if stats.DroppedFrames != tt.wantDropped {
+				t.Errorf("DroppedFrames = %d, want %d", stats.DroppedFrames, tt.wantDropped)
+			}
+			if stats.QueuedFrames != int64(tt.wantQueued) {
+				t.Errorf("QueuedFrames = %d, want %d", stats.QueuedFrames, tt.wantQueued)
+			}
+
+			// Queued frames are replayed to the next connection.
+			if tt.wantQueued > 0 {
+				next := newTestClient(hub, 1)
+				if err := hub.Register(next); err != nil {
+					t.Fatalf("Register() error = %v", err)
+				}
+				if got := string(<-next.send); got != `{"n":1}` {
+					t.Errorf("replayed frame = %s, want %s", got, `{"n":1}`)
+				}
+				if len(pending.frames[1]) != 0 {
+					t.Errorf("pending frames left = %d", len(pending.frames[1]))
+				}
+			}
+		})
+	}
+}
+
+func TestHubReplayKeepsFramesThatDoNotFit(t *testing.T) {
+	hub, pending := newTestHub(SlowConsumerQueue, 2)
+	for i := 0; i < 5; i++ {
+		pending.Push(context.Background(), 1, []byte(fmt.Sprintf(`{"n":%d}`, i)), 100, time.Minute)
+	}
+
+	client := newTestClient(hub, 1)
+	if err := hub.Register(client); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	var replayed []string
+	for len(client.send) > 0 {
+		replayed = append(replayed, string(<-client.send))
+	}
+	if want := []string{`{"n":0}`, `{"n":1}`}; fmt.Sprint(replayed) != fmt.Sprint(want) {
+		t.Errorf("replayed = %v, want %v", replayed, want)
+	}
+
+	var left []string
+	for _, frame := range pending.frames[1] {
+		left = append(left, string(frame))
+	}
+	if want := []string{`{"n":2}`, `{"n":3}`, `{"n":4}`}; fmt.Sprint(left) != fmt.Sprint(want) {
+		t.Errorf("pending frames = %v, want %v", left, want)
+	}
+	if dropped := hub.DeliveryStats().DroppedFrames; dropped != 0 {
+		t.Errorf("DroppedFrames = %d, want 0", dropped)
+	}
+}
+
+func TestHubSlowConsumersUnderLoad(t *testing.T) {
+	for _, policy := range []string{SlowConsumerDropOldest, SlowConsumerDisconnect, SlowConsumerQueue} {
+		t.Run(policy, func(t *testing.T) {
+			hub, _ := newTestHub(policy, 2)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				client := newTestClient(hub, 1)
+				// Half of the clients keep up, half never read.
+				register := hub.Register
+				if i%2 == 0 {
+					register = serve
+				}
+				if err := register(client); err != nil {
+					t.Fatalf("Register() error = %v", err)
+				}
+			}
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 20; j++ {
+						hub.NotifyUser(context.Background(), 1, "test", i*100+j)
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			stats := hub.DeliveryStats()
+			if stats.BufferOverflows == 0 {
+				t.Error("expected buffer overflows")
+			}
+			if policy != SlowConsumerDropOldest && stats.SlowConsumerDisconnects == 0 {
+				t.Error("expected slow consumer disconnects")
+			}
+		})
+	}
+}
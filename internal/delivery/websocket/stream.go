@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// Stream is a subscription used by the SSE and long-poll transports. It does
+// not buffer frames: it is woken up on new events and reads them from the
+// hub's event history, so it can never fall behind the way a WebSocket
+// client can.
+type Stream struct {
+	userID  uint
+	wake    chan struct{}
+	closing chan struct{}
+	done    chan struct{}
+
+	mu          sync.Mutex
+	closed      bool
+	closeCode   int
+	closeReason string
+	finishOnce  sync.Once
+}
+
+func newStream(userID uint) *Stream {
+	return &Stream{
+		userID:  userID,
+		wake:    make(chan struct{}, 1),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Wake receives a value when new events are available.
+func (s *Stream) Wake() <-chan struct{} {
+	return s.wake
+}
+
+// Closing is closed when the server ends the subscription.
+func (s *Stream) Closing() <-chan struct{} {
+	return s.closing
+}
+
+// CloseReason returns the close code and reason once Closing is closed.
+func (s *Stream) CloseReason() (int, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeCode, s.closeReason
+}
+
+func (s *Stream) user() uint {
+	return s.userID
+}
+
+func (s *Stream) trySend([]byte) bool {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+func (s *Stream) replaceOldest([]byte) {}
+
+// close ends the stream. closeFrame uses the WebSocket close payload format
+// so the same code and reason reach every transport.
+func (s *Stream) close(closeFrame []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+	s.closed = true
+	if len(closeFrame) >= 2 {
+		s.closeCode = int(binary.BigEndian.Uint16(closeFrame))
+		s.closeReason = string(closeFrame[2:])
+	}
+	close(s.closing)
+	return true
+}
+
+func (s *Stream) finish() {
+	s.finishOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *Stream) finished() <-chan struct{} {
+	return s.done
+}
+
+// abort is a no-op: the transport returns as soon as Closing is closed.
+func (s *Stream) abort() {}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	AuditActionAccountLocked   = "auth.account_locked"
+	AuditActionIPLocked        = "auth.ip_locked"
+	AuditActionAccountUnlocked = "auth.account_unlocked"
+
+	AuditActionUserSuspended       = "admin.user_suspended"
+	AuditActionUserUnsuspended     = "admin.user_unsuspended"
+	AuditActionRoleChanged         = "admin.role_changed"
+	AuditActionPasswordResetForced = "admin.password_reset_forced"
+	AuditActionPasswordReset       = "auth.password_reset"
+
+	AuditActionFlagApproved = "moderation.flag_approved"
+	AuditActionFlagRemoved  = "moderation.flag_removed"
+
+	AuditActionReportTriaged   = "moderation.report_triaged"
+	AuditActionReportResolved  = "moderation.report_resolved"
+	AuditActionReportDismissed = "moderation.report_dismissed"
+	AuditActionMessageDeleted  = "moderation.message_deleted"
+
+	AuditActionWebhookCreated     = "admin.webhook_created"
+	AuditActionWebhookUpdated     = "admin.webhook_updated"
+	AuditActionWebhookDeleted     = "admin.webhook_deleted"
+	AuditActionWebhookRedelivered = "admin.webhook_redelivered"
+
+	AuditActionBotCreated        = "admin.bot_created"
+	AuditActionBotUpdated        = "admin.bot_updated"
+	AuditActionBotTokenCreated   = "admin.bot_token_created"
+	AuditActionBotTokenRevoked   = "admin.bot_token_revoked"
+	AuditActionBotWebhookSet     = "admin.bot_webhook_set"
+	AuditActionBotWebhookRemoved = "admin.bot_webhook_removed"
+)
+
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ActorID    *uint     `json:"actor_id" gorm:"index"`
+	Action     string    `json:"action" gorm:"type:varchar(100);not null;index"`
+	TargetType string    `json:"target_type" gorm:"type:varchar(50)"`
+	TargetID   string    `json:"target_id" gorm:"type:varchar(100)"`
+	IP         string    `json:"ip" gorm:"type:varchar(45)"`
+	Metadata   string    `json:"metadata" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
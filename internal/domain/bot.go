@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"time"
+)
+
+// Scopes a bot token can be granted.
+const (
+	BotScopeMessagesWrite = "messages:write"
+	BotScopeMessagesRead  = "messages:read"
+	BotScopeEventsRead    = "events:read"
+)
+
+// Bot is an automated account. It has its own user row (with IsBot set) so
+// it can take part in conversations like any other user, but it signs in
+// with API tokens instead of a password.
+type Bot struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex"`
+	CreatedBy   uint      `json:"created_by" gorm:"not null"`
+	Description string    `json:"description" gorm:"type:varchar(255)"`
+	WebhookID   *uint     `json:"webhook_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	User    User     `json:"user" gorm:"foreignKey:UserID"`
+	Webhook *Webhook `json:"webhook,omitempty" gorm:"foreignKey:WebhookID;constraint:OnDelete:SET NULL"`
+}
+
+// BotToken is an API token of a bot. Only the SHA-256 hash of the token is
+// stored; Prefix identifies it in listings.
+type BotToken struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	BotID      uint       `json:"bot_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"type:varchar(100);not null"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(20);not null"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Scopes     StringList `json:"scopes" gorm:"type:varchar(255);not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (t *BotToken) HasScope(scope string) bool {
+	return t.Scopes.Has(scope)
+}
+
+type CreateBotRequest struct {
+	Username    string `json:"username" binding:"required,min=3,max=50"`
+	Fullname    string `json:"fullname" binding:"required,min=3,max=50"`
+	Photo       string `json:"photo" binding:"max=255"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+type UpdateBotRequest struct {
+	Fullname    *string `json:"fullname" binding:"omitempty,min=3,max=50"`
+	Photo       *string `json:"photo" binding:"omitempty,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=255"`
+}
+
+type CreateBotTokenRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=messages:write messages:read events:read"`
+}
+
+type SetBotWebhookRequest struct {
+	URL string `json:"url" binding:"required,url,max=500"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	ContactStatusPending  = "pending"
+	ContactStatusAccepted = "accepted"
+	ContactStatusDeclined = "declined"
+)
+
+const (
+	EventContactRequest   = "contact.request"
+	EventContactAccepted  = "contact.accepted"
+	EventContactCancelled = "contact.cancelled"
+)
+
+// Contact is a friend request from RequesterID to AddresseeID. Once accepted
+// the two users are contacts of each other.
+type Contact struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	RequesterID uint      `json:"requester_id" gorm:"not null;uniqueIndex:idx_requester_addressee"`
+	AddresseeID uint      `json:"addressee_id" gorm:"not null;uniqueIndex:idx_requester_addressee;index"`
+	Status      string    `json:"status" gorm:"type:varchar(20);not null;default:pending"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Requester User `json:"-" gorm:"foreignKey:RequesterID"`
+	Addressee User `json:"-" gorm:"foreignKey:AddresseeID"`
+}
+
+// OtherUser returns the user on the other side of the contact from userID.
+func (c *Contact) OtherUser(userID uint) User {
+	if c.RequesterID == userID {
+		return c.Addressee
+	}
+	return c.Requester
+}
+
+type ContactRequestInput struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+type ContactRequestResponse struct {
+	ID        uint      `json:"id"`
+	Status    string    `json:"status"`
+	From      UserInfo  `json:"from"`
+	To        UserInfo  `json:"to"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ContactResponse struct {
+	UserID   uint      `json:"user_id"`
+	Fullname string    `json:"fullname"`
+	Photo    string    `json:"photo"`
+	Username string    `json:"username"`
+	Online   bool      `json:"online"`
+	Since    time.Time `json:"since"`
+}
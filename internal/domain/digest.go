@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	DigestFrequencyDaily  = "daily"
+	DigestFrequencyWeekly = "weekly"
+)
+
+// DigestPreferences controls the email digest of unread messages. Users
+// without a row get the defaults.
+type DigestPreferences struct {
+	UserID     uint       `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Enabled    bool       `json:"enabled" gorm:"not null;default:true"`
+	Frequency  string     `json:"frequency" gorm:"type:varchar(20);not null;default:daily"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func DefaultDigestPreferences(userID uint) *DigestPreferences {
+	return &DigestPreferences{
+		UserID:    userID,
+		Enabled:   true,
+		Frequency: DigestFrequencyDaily,
+	}
+}
+
+// Interval is the minimum time between two digests.
+func (p *DigestPreferences) Interval() time.Duration {
+	if p.Frequency == DigestFrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+type UpdateDigestPreferencesRequest struct {
+	Enabled   *bool  `json:"enabled"`
+	Frequency string `json:"frequency" binding:"omitempty,oneof=daily weekly"`
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Domain events published on the event bus. The names double as webhook
+// event types.
+const (
+	EventMessageCreated = "message.created"
+	EventMessageRead    = "message.read"
+	EventUserRegistered = "user.registered"
+	EventBotCommand     = "bot.command"
+)
+
+// UserScopedEvent is implemented by events that concern specific users, so
+// they can be routed to webhooks registered for one user (e.g. a bot).
+type UserScopedEvent interface {
+	Involves(userID uint) bool
+}
+
+type MessageCreatedEvent struct {
+	ID         uint      `json:"id"`
+	SenderID   uint      `json:"sender_id"`
+	ReceiverID uint      `json:"receiver_id"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Message is the stored message with sender and receiver, as pushed to
+	// live connections. It is not part of the webhook payload.
+	Message *Message `json:"-"`
+}
+
+func (e MessageCreatedEvent) Involves(userID uint) bool {
+	return e.SenderID == userID || e.ReceiverID == userID
+}
+
+type MessageReadEvent struct {
+	ID         uint      `json:"id"`
+	SenderID   uint      `json:"sender_id"`
+	ReceiverID uint      `json:"receiver_id"`
+	ReadAt     time.Time `json:"read_at"`
+}
+
+func (e MessageReadEvent) Involves(userID uint) bool {
+	return e.SenderID == userID || e.ReceiverID == userID
+}
+
+type UserRegisteredEvent struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Fullname  string    `json:"fullname"`
+	Email     string    `json:"email"`
+	Provider  string    `json:"provider"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BotCommandEvent is a slash command ("/weather berlin") sent to a bot.
+type BotCommandEvent struct {
+	MessageID uint      `json:"message_id"`
+	BotUserID uint      `json:"bot_user_id"`
+	SenderID  uint      `json:"sender_id"`
+	Command   string    `json:"command"`
+	Args      []string  `json:"args"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (e BotCommandEvent) Involves(userID uint) bool {
+	return e.BotUserID == userID || e.SenderID == userID
+}
+
+// storedMessageCreatedEvent is how a message.created event is stored: with
+// the full message, which its own JSON leaves out.
+type storedMessageCreatedEvent struct {
+	MessageCreatedEvent
+	Message *Message `json:"message,omitempty"`
+}
+
+// EncodeEvent turns an event payload into what is stored, so that
+// DecodeEvent returns it in the same shape as it was published.
+func EncodeEvent(payload interface{}) ([]byte, error) {
+	if event, ok := payload.(MessageCreatedEvent); ok {
+		return json.Marshal(storedMessageCreatedEvent{MessageCreatedEvent: event, Message: event.Message})
+	}
+	return json.Marshal(payload)
+}
+
+// DecodeEvent turns a stored event payload back into the event struct that
+// subscribers expect.
+func DecodeEvent(eventType string, data []byte) (interface{}, error) {
+	switch eventType {
+	case EventMessageCreated:
+		var stored storedMessageCreatedEvent
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, err
+		}
+		event := stored.MessageCreatedEvent
+		event.Message = stored.Message
+		return event, nil
+	case EventMessageRead:
+		return decodeEvent[MessageReadEvent](data)
+	case EventUserRegistered:
+		return decodeEvent[UserRegisteredEvent](data)
+	case EventBotCommand:
+		return decodeEvent[BotCommandEvent](data)
+	default:
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+}
+
+func decodeEvent[T any](data []byte) (interface{}, error) {
+	var event T
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestEncodeEventKeepsMessage(t *testing.T) {
+	event := MessageCreatedEvent{
+		ID:         1,
+		SenderID:   2,
+		ReceiverID: 3,
+		Content:    "hi",
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		Message: &Message{
+			ID:       1,
+			Content:  "hi",
+			Sender:   User{ID: 2, Username: "alice"},
+			Receiver: User{ID: 3, Username: "bob"},
+		},
+	}
+
+	data, err := EncodeEvent(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeEvent(EventMessageCreated, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := decoded.(MessageCreatedEvent)
+	if got.Message == nil || got.Message.Sender.Username != "alice" || got.Message.Receiver.Username != "bob" {
+		t.Fatalf("decoded message = %+v, want the full message", got.Message)
+	}
+
+	// Subscribers see the same JSON, e.g. in webhook payloads.
+	want, _ := json.Marshal(event)
+	if gotJSON, _ := json.Marshal(got); string(gotJSON) != string(want) {
+		t.Errorf("decoded JSON = %s, want %s", gotJSON, want)
+	}
+}
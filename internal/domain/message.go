@@ -34,6 +34,7 @@ type MessageResponse struct {
 type UserInfo struct {
 	ID       uint   `json:"id"`
 	Username string `json:"username"`
+	IsBot    bool   `json:"is_bot"`
 }
 
 type ChatListItem struct {
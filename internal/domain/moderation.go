@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	FlagStatusPending  = "pending"
+	FlagStatusApproved = "approved"
+	FlagStatusRemoved  = "removed"
+)
+
+// ModerationFlag records a message a filter asked a human to review. The
+// message has already been delivered; removing it deletes the message.
+type ModerationFlag struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	MessageID  uint       `json:"message_id" gorm:"not null;index"`
+	SenderID   uint       `json:"sender_id" gorm:"not null;index"`
+	ReceiverID uint       `json:"receiver_id" gorm:"not null"`
+	Content    string     `json:"content" gorm:"type:text;not null"`
+	Filter     string     `json:"filter" gorm:"type:varchar(50);not null"`
+	Reason     string     `json:"reason" gorm:"type:varchar(255)"`
+	Status     string     `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	ReviewedBy *uint      `json:"reviewed_by"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ReviewFlagRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve remove"`
+}
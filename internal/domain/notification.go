@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+)
+
+// DeviceToken is a push notification token of one of the user's devices.
+type DeviceToken struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	Platform   string    `json:"platform" gorm:"type:varchar(20);not null"`
+	Token      string    `json:"-" gorm:"type:varchar(255);not null;uniqueIndex"`
+	DeviceName string    `json:"device_name" gorm:"type:varchar(100)"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ConversationMute stops push notifications for messages from another user,
+// until MutedUntil or, if it is nil, until the conversation is unmuted.
+type ConversationMute struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_user_other"`
+	OtherUserID uint       `json:"other_user_id" gorm:"not null;uniqueIndex:idx_user_other"`
+	MutedUntil  *time.Time `json:"muted_until"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	OtherUser User `json:"-" gorm:"foreignKey:OtherUserID"`
+}
+
+type RegisterDeviceRequest struct {
+	Platform   string `json:"platform" binding:"required,oneof=android ios web"`
+	Token      string `json:"token" binding:"required,max=255"`
+	DeviceName string `json:"device_name" binding:"max=100"`
+}
+
+type MuteConversationRequest struct {
+	// MutedUntil is optional; without it the conversation stays muted.
+	MutedUntil *time.Time `json:"muted_until"`
+}
+
+type ConversationMuteResponse struct {
+	User       UserInfo   `json:"user"`
+	MutedUntil *time.Time `json:"muted_until"`
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the change
+// that caused it, so it is published even if the process dies right after
+// the commit.
+type OutboxEvent struct {
+	ID         uint      `gorm:"primaryKey"`
+	EventID    string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	EventType  string    `gorm:"type:varchar(50);not null"`
+	Payload    string    `gorm:"type:text;not null"`
+	OccurredAt time.Time `gorm:"not null"`
+	// LockedUntil is set while a process is publishing the event; the relay
+	// only picks up unprocessed events whose lock has expired.
+	LockedUntil *time.Time `gorm:"index:idx_pending"`
+	ProcessedAt *time.Time `gorm:"index:idx_pending"`
+	Attempts    int        `gorm:"not null;default:0"`
+	LastError   string     `gorm:"type:text"`
+	CreatedAt   time.Time
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	VisibilityEveryone = "everyone"
+	VisibilityContacts = "contacts"
+	VisibilityNobody   = "nobody"
+)
+
+type UserBlock struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BlockerID uint      `json:"blocker_id" gorm:"not null;uniqueIndex:idx_blocker_blocked"`
+	BlockedID uint      `json:"blocked_id" gorm:"not null;uniqueIndex:idx_blocker_blocked;index"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Blocked User `json:"blocked" gorm:"foreignKey:BlockedID"`
+}
+
+// PrivacySettings controls who can message a user and who can see their
+// last-seen time and read receipts. Users without a row get the defaults.
+type PrivacySettings struct {
+	UserID             uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	MessagePermission  string    `json:"message_permission" gorm:"type:varchar(20);not null;default:everyone"`
+	LastSeenVisibility string    `json:"last_seen_visibility" gorm:"type:varchar(20);not null;default:everyone"`
+	ReadReceipts       string    `json:"read_receipts" gorm:"type:varchar(20);not null;default:everyone"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+func DefaultPrivacySettings(userID uint) *PrivacySettings {
+	return &PrivacySettings{
+		UserID:             userID,
+		MessagePermission:  VisibilityEveryone,
+		LastSeenVisibility: VisibilityEveryone,
+		ReadReceipts:       VisibilityEveryone,
+	}
+}
+
+type UpdatePrivacyRequest struct {
+	MessagePermission  string `json:"message_permission" binding:"omitempty,oneof=everyone contacts"`
+	LastSeenVisibility string `json:"last_seen_visibility" binding:"omitempty,oneof=everyone contacts nobody"`
+	ReadReceipts       string `json:"read_receipts" binding:"omitempty,oneof=everyone contacts nobody"`
+}
+
+type Presence struct {
+	UserID     uint       `json:"user_id"`
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	ReportTargetMessage = "message"
+	ReportTargetUser    = "user"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusInReview  = "in_review"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+const (
+	ReportActionNone          = "none"
+	ReportActionDeleteMessage = "delete_message"
+	ReportActionSuspendUser   = "suspend_user"
+)
+
+// Report is a user complaint about a message or an account. Snapshot keeps a
+// JSON copy of the reported content as it was when reported, so it can still
+// be reviewed if the message is edited or deleted.
+type Report struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	ReporterID     uint       `json:"reporter_id" gorm:"not null;index"`
+	TargetType     string     `json:"target_type" gorm:"type:varchar(20);not null;index:idx_target"`
+	TargetID       uint       `json:"target_id" gorm:"not null;index:idx_target"`
+	Reason         string     `json:"reason" gorm:"type:varchar(50);not null"`
+	Details        string     `json:"details" gorm:"type:text"`
+	Snapshot       string     `json:"snapshot" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:open;index"`
+	AssignedTo     *uint      `json:"assigned_to"`
+	Resolution     string     `json:"resolution" gorm:"type:varchar(50)"`
+	ResolutionNote string     `json:"resolution_note" gorm:"type:text"`
+	ResolvedBy     *uint      `json:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type CreateReportRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=message user"`
+	TargetID   uint   `json:"target_id" binding:"required"`
+	Reason     string `json:"reason" binding:"required,oneof=spam harassment hate_speech inappropriate_content impersonation other"`
+	Details    string `json:"details" binding:"max=1000"`
+}
+
+type ResolveReportRequest struct {
+	Status string `json:"status" binding:"required,oneof=resolved dismissed"`
+	Action string `json:"action" binding:"omitempty,oneof=none delete_message suspend_user"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+type ReportFilter struct {
+	Status     string
+	TargetType string
+	Limit      int
+	Offset     int
+}
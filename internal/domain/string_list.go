@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+)
+
+// StringList is a list of identifiers stored as a comma separated string.
+type StringList []string
+
+func (e StringList) Value() (driver.Value, error) {
+	return strings.Join(e, ","), nil
+}
+
+func (e *StringList) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case nil:
+	default:
+		return errors.New("invalid event types value")
+	}
+
+	*e = nil
+	if s != "" {
+		*e = strings.Split(s, ",")
+	}
+	return nil
+}
+
+func (e StringList) Has(value string) bool {
+	for _, v := range e {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
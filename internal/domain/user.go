@@ -4,15 +4,52 @@ import (
 	"time"
 )
 
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Fullname  string    `json:"fullname" gorm:"type:varchar(50);not null"`
-	Photo     string    `json:"photo" gorm:"type:varchar(255)"`
-	Username  string    `json:"username" gorm:"type:varchar(50);unique;not null"`
-	Email     string    `json:"email" gorm:"type:varchar(100);unique;not null"`
-	Password  string    `json:"-" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                    uint       `json:"id" gorm:"primaryKey"`
+	Fullname              string     `json:"fullname" gorm:"type:varchar(50);not null"`
+	Photo                 string     `json:"photo" gorm:"type:varchar(255)"`
+	Username              string     `json:"username" gorm:"type:varchar(50);unique;not null"`
+	Email                 string     `json:"email" gorm:"type:varchar(100);unique;not null"`
+	Password              string     `json:"-" gorm:"not null"`
+	Role                  string     `json:"-" gorm:"type:varchar(20);not null;default:user"`
+	IsBot                 bool       `json:"is_bot" gorm:"not null;default:false"`
+	SuspendedAt           *time.Time `json:"-"`
+	PasswordResetRequired bool       `json:"-" gorm:"default:false"`
+	LastSeenAt            *time.Time `json:"-"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+func (u *User) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// roleRanks orders the roles by privilege. Unknown roles rank lowest.
+var roleRanks = map[string]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+// Outranks reports whether the user's role is strictly higher than the
+// other user's, which is required to act on their account.
+func (u *User) Outranks(other *User) bool {
+	return roleRanks[u.Role] > roleRanks[other.Role]
 }
 
 type UserRegisterRequest struct {
@@ -34,5 +71,60 @@ type UserResponse struct {
 	Photo    string `json:"photo"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Role     string `json:"role,omitempty"`
 	Token    string `json:"token,omitempty"`
 }
+
+// AdminUserResponse is a user as listed to admins, with the role and
+// moderation state that User keeps out of its JSON.
+type AdminUserResponse struct {
+	ID                    uint       `json:"id"`
+	Fullname              string     `json:"fullname"`
+	Photo                 string     `json:"photo"`
+	Username              string     `json:"username"`
+	Email                 string     `json:"email"`
+	Role                  string     `json:"role"`
+	IsBot                 bool       `json:"is_bot"`
+	SuspendedAt           *time.Time `json:"suspended_at"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+type SystemStats struct {
+	TotalUsers       int64 `json:"total_users"`
+	SuspendedUsers   int64 `json:"suspended_users"`
+	ConnectedClients int   `json:"connected_clients"`
+	ConnectedUsers   int   `json:"connected_users"`
+	TotalMessages    int64 `json:"total_messages"`
+	MessagesLastHour int64 `json:"messages_last_hour"`
+	MessagesLast24h  int64 `json:"messages_last_24h"`
+
+	Delivery DeliveryStats `json:"delivery"`
+	// Events counts domain events published since the server started.
+	Events map[string]int64 `json:"events"`
+}
+
+// DeliveryStats counts WebSocket frames delivered to clients, and those that
+// could not be written to slow clients, since the server started.
+type DeliveryStats struct {
+	SlowConsumerPolicy      string `json:"slow_consumer_policy"`
+	DeliveredFrames         int64  `json:"delivered_frames"`
+	BufferOverflows         int64  `json:"buffer_overflows"`
+	DroppedFrames           int64  `json:"dropped_frames"`
+	QueuedFrames            int64  `json:"queued_frames"`
+	SlowConsumerDisconnects int64  `json:"slow_consumer_disconnects"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+)
+
+// UserIdentity links an external OpenID Connect subject to a local user.
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Provider  string    `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_provider_subject"`
+	Subject   string    `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_subject"`
+	Email     string    `json:"email" gorm:"type:varchar(100)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+type OIDCAuthStart struct {
+	AuthURL string
+	State   string
+}
+
+type OIDCCallbackRequest struct {
+	Code             string
+	State            string
+	StateCookie      string
+	Error            string
+	ErrorDescription string
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserJSONHidesModerationState(t *testing.T) {
+	now := time.Now()
+	data, err := json.Marshal(User{ID: 1, Role: RoleAdmin, SuspendedAt: &now, PasswordResetRequired: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{`"role"`, `"suspended_at"`, `"password_reset_required"`} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("user JSON %s contains %s", data, key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"time"
+)
+
+// WebhookEventTypes are the events a webhook can subscribe to.
+var WebhookEventTypes = []string{
+	EventMessageCreated,
+	EventMessageRead,
+	EventUserRegistered,
+	EventBotCommand,
+}
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	// DeliveryStatusDead marks a delivery that ran out of attempts. It stays
+	// in the log until it is redelivered manually.
+	DeliveryStatusDead = "dead"
+)
+
+// Webhook is an HTTP endpoint that receives signed event payloads.
+type Webhook struct {
+	ID        uint `json:"id" gorm:"primaryKey"`
+	CreatedBy uint `json:"created_by" gorm:"not null"`
+	// UserID limits the webhook to events involving that user. It is set
+	// for bot webhooks.
+	UserID      *uint      `json:"user_id,omitempty" gorm:"index"`
+	URL         string     `json:"url" gorm:"type:varchar(500);not null"`
+	Description string     `json:"description" gorm:"type:varchar(255)"`
+	Events      StringList `json:"events" gorm:"type:varchar(255);not null"`
+	Secret      string     `json:"-" gorm:"type:varchar(100);not null"`
+	Active      bool       `json:"active" gorm:"not null;default:true;index"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one webhook. Payload holds the
+// exact body so every attempt sends the same bytes.
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	WebhookID      uint       `json:"webhook_id" gorm:"not null;index"`
+	EventID        string     `json:"event_id" gorm:"type:varchar(64);not null;index"`
+	EventType      string     `json:"event_type" gorm:"type:varchar(50);not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:pending;index:idx_due"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_due"`
+	LockedUntil    *time.Time `json:"-"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+	ResponseStatus int        `json:"response_status"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	Webhook     *Webhook                 `json:"-" gorm:"foreignKey:WebhookID"`
+	AttemptLogs []WebhookDeliveryAttempt `json:"attempt_logs,omitempty" gorm:"foreignKey:DeliveryID"`
+}
+
+// WebhookDeliveryAttempt logs a single HTTP request made for a delivery.
+type WebhookDeliveryAttempt struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	DeliveryID   uint      `json:"delivery_id" gorm:"not null;index"`
+	StatusCode   int       `json:"status_code"`
+	Error        string    `json:"error" gorm:"type:text"`
+	ResponseBody string    `json:"response_body" gorm:"type:text"`
+	DurationMs   int64     `json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=500"`
+	Description string   `json:"description" binding:"max=255"`
+	Events      []string `json:"events" binding:"required,min=1,dive,oneof=message.created message.read user.registered bot.command"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" binding:"omitempty,url,max=500"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Events      []string `json:"events" binding:"omitempty,min=1,dive,oneof=message.created message.read user.registered bot.command"`
+	Active      *bool    `json:"active"`
+}
+
+type WebhookDeliveryFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
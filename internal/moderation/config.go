@@ -0,0 +1,63 @@
+package moderation
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+)
+
+// NewFromConfig builds the default chain: profanity, then blocked links,
+// then spam detection.
+func NewFromConfig(cfg *config.Config) *Chain {
+	words := append([]string{}, cfg.ModerationProfanityWords...)
+	if cfg.ModerationProfanityFile != "" {
+		fileWords, err := loadWordList(cfg.ModerationProfanityFile)
+		if err != nil {
+			slog.Warn("Failed to load profanity list", "file", cfg.ModerationProfanityFile, "error", err)
+		}
+		words = append(words, fileWords...)
+	}
+
+	return NewChain(
+		NewProfanityFilter(words, actionOrDefault(cfg.ModerationProfanityAction, ActionMask)),
+		NewLinkFilter(cfg.ModerationBlockedDomains, actionOrDefault(cfg.ModerationLinkAction, ActionReject)),
+		NewSpamFilter(
+			cfg.ModerationSpamRepeatLimit,
+			time.Duration(cfg.ModerationSpamWindow)*time.Second,
+			actionOrDefault(cfg.ModerationSpamAction, ActionReject),
+		),
+	)
+}
+
+// loadWordList reads one word per line, ignoring blank lines and # comments.
+func loadWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+func actionOrDefault(value string, fallback Action) Action {
+	action, err := ParseAction(value)
+	if err != nil {
+		slog.Warn("Invalid moderation action", "error", err, "fallback", fallback)
+		return fallback
+	}
+	return action
+}
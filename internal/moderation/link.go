@@ -0,0 +1,73 @@
+package moderation
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://)?(?:[a-z0-9-]+\.)+[a-z]{2,}(?::\d+)?(?:/\S*)?`)
+
+// LinkFilter acts on messages linking to a blocked domain or any of its
+// subdomains.
+type LinkFilter struct {
+	domains []string
+	action  Action
+}
+
+func NewLinkFilter(domains []string, action Action) *LinkFilter {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "*.")
+		if domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return &LinkFilter{domains: normalized, action: action}
+}
+
+func (f *LinkFilter) Name() string {
+	return "link"
+}
+
+func (f *LinkFilter) Check(input *Input) (Action, string, string) {
+	if len(f.domains) == 0 {
+		return ActionAllow, "", ""
+	}
+
+	masked := input.Content
+	found := false
+
+	for _, link := range linkPattern.FindAllString(input.Content, -1) {
+		if !f.blocked(hostOf(link)) {
+			continue
+		}
+		found = true
+		masked = strings.ReplaceAll(masked, link, "[link removed]")
+	}
+
+	if !found {
+		return ActionAllow, "", ""
+	}
+	return f.action, masked, "message links to a blocked domain"
+}
+
+func (f *LinkFilter) blocked(host string) bool {
+	for _, domain := range f.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostOf(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
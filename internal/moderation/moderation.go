@@ -0,0 +1,132 @@
+package moderation
+
+import "fmt"
+
+type Action int
+
+// Actions are ordered by severity so the chain can keep the strongest one.
+const (
+	ActionAllow Action = iota
+	ActionMask
+	ActionFlag
+	ActionReject
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionMask:
+		return "mask"
+	case ActionFlag:
+		return "flag"
+	case ActionReject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+func ParseAction(value string) (Action, error) {
+	switch value {
+	case "allow":
+		return ActionAllow, nil
+	case "mask":
+		return ActionMask, nil
+	case "flag":
+		return ActionFlag, nil
+	case "reject":
+		return ActionReject, nil
+	}
+	return ActionAllow, fmt.Errorf("unknown moderation action %q", value)
+}
+
+type Input struct {
+	SenderID   uint
+	ReceiverID uint
+	Content    string
+}
+
+// Verdict is what a single filter decided about a message.
+type Verdict struct {
+	Filter string
+	Action Action
+	Reason string
+}
+
+type Result struct {
+	Action   Action
+	Content  string
+	Verdicts []Verdict
+}
+
+// Flagged returns the verdicts that asked for human review.
+func (r *Result) Flagged() []Verdict {
+	var flagged []Verdict
+	for _, v := range r.Verdicts {
+		if v.Action == ActionFlag {
+			flagged = append(flagged, v)
+		}
+	}
+	return flagged
+}
+
+// RejectReason returns the reason given by the first rejecting filter.
+func (r *Result) RejectReason() string {
+	for _, v := range r.Verdicts {
+		if v.Action == ActionReject {
+			return v.Reason
+		}
+	}
+	return ""
+}
+
+type Moderator interface {
+	Moderate(input *Input) Result
+}
+
+// Filter inspects a message. It returns ActionAllow when it has nothing to
+// say; for ActionMask it also returns the masked content.
+type Filter interface {
+	Name() string
+	Check(input *Input) (action Action, content string, reason string)
+}
+
+// Chain runs filters in order. Masks are applied cumulatively, a reject
+// stops the chain, and the strongest action seen becomes the result.
+type Chain struct {
+	filters []Filter
+}
+
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{filters: filters}
+}
+
+func (c *Chain) Moderate(input *Input) Result {
+	result := Result{Action: ActionAllow, Content: input.Content}
+	current := *input
+
+	for _, filter := range c.filters {
+		action, content, reason := filter.Check(&current)
+		if action == ActionAllow {
+			continue
+		}
+
+		result.Verdicts = append(result.Verdicts, Verdict{
+			Filter: filter.Name(),
+			Action: action,
+			Reason: reason,
+		})
+		if action > result.Action {
+			result.Action = action
+		}
+
+		if action == ActionMask {
+			current.Content = content
+			result.Content = content
+		}
+		if action == ActionReject {
+			break
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,72 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+var leetReplacer = strings.NewReplacer(
+	"0", "o",
+	"1", "i",
+	"!", "i",
+	"3", "e",
+	"4", "a",
+	"@", "a",
+	"5", "s",
+	"$", "s",
+	"7", "t",
+	"+", "t",
+)
+
+// normalize folds a word so simple evasions match the word list: case,
+// diacritics ("fück") and leetspeak ("sh1t"). Symbols only stand for letters
+// inside the word, so "shit!" is "shit" and not "shiti".
+func normalize(word string) string {
+	word = strings.ToLower(trimSymbols(word))
+
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if folded, _, err := transform.String(t, word); err == nil {
+		word = folded
+	}
+
+	word = leetReplacer.Replace(word)
+
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return r
+		}
+		return -1
+	}, word)
+}
+
+// collapse squeezes runs of the same letter ("shiiit" -> "shit").
+func collapse(word string) string {
+	var b strings.Builder
+	var last rune
+	for _, r := range word {
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
+
+// trimSymbols strips the leading and trailing punctuation from a word.
+func trimSymbols(word string) string {
+	return strings.TrimFunc(word, isSymbol)
+}
+
+func isSymbol(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// isWordRune reports whether r can be part of a word, including the
+// characters commonly used as leetspeak substitutes.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("@$!+", r)
+}
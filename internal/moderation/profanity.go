@@ -0,0 +1,94 @@
+package moderation
+
+import (
+	"strings"
+)
+
+type ProfanityFilter struct {
+	words     map[string]struct{}
+	collapsed map[string]struct{}
+	action    Action
+}
+
+func NewProfanityFilter(words []string, action Action) *ProfanityFilter {
+	f := &ProfanityFilter{
+		words:     make(map[string]struct{}, len(words)),
+		collapsed: make(map[string]struct{}, len(words)),
+		action:    action,
+	}
+	for _, word := range words {
+		if normalized := normalize(word); normalized != "" {
+			f.words[normalized] = struct{}{}
+			f.collapsed[collapse(normalized)] = struct{}{}
+		}
+	}
+	return f
+}
+
+// matches compares the collapsed form only when the word actually has
+// repeated letters, so "as" does not match a list entry "ass".
+func (f *ProfanityFilter) matches(word string) bool {
+	normalized := normalize(word)
+	if _, ok := f.words[normalized]; ok {
+		return true
+	}
+
+	collapsed := collapse(normalized)
+	if collapsed == normalized {
+		return false
+	}
+	_, ok := f.collapsed[collapsed]
+	return ok
+}
+
+func (f *ProfanityFilter) Name() string {
+	return "profanity"
+}
+
+func (f *ProfanityFilter) Check(input *Input) (Action, string, string) {
+	if len(f.words) == 0 {
+		return ActionAllow, "", ""
+	}
+
+	var b strings.Builder
+	found := false
+
+	content := []rune(input.Content)
+	for i := 0; i < len(content); {
+		if !isWordRune(content[i]) {
+			b.WriteRune(content[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(content) && isWordRune(content[j]) {
+			j++
+		}
+
+		// Punctuation around the word is kept as is: "damn!!" -> "****!!".
+		start, end := i, j
+		for start < end && isSymbol(content[start]) {
+			start++
+		}
+		for end > start && isSymbol(content[end-1]) {
+			end--
+		}
+
+		b.WriteString(string(content[i:start]))
+		word := string(content[start:end])
+		if word != "" && f.matches(word) {
+			found = true
+			b.WriteString(strings.Repeat("*", end-start))
+		} else {
+			b.WriteString(word)
+		}
+		b.WriteString(string(content[end:j]))
+		i = j
+	}
+
+	if !found {
+		return ActionAllow, "", ""
+	}
+	return f.action, b.String(), "message contains inappropriate language"
+}
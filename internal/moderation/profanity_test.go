@@ -0,0 +1,65 @@
+package moderation
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "Shit", want: "shit"},
+		{word: "fück", want: "fuck"},
+		{word: "sh1t", want: "shit"},
+		{word: "sh!t", want: "shit"},
+		{word: "m@ss", want: "mass"},
+		{word: "shit!", want: "shit"},
+		{word: "damn!!", want: "damn"},
+		{word: "!!!", want: ""},
+		{word: "@home", want: "home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := normalize(tt.word); got != tt.want {
+				t.Errorf("normalize(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfanityFilterCheck(t *testing.T) {
+	filter := NewProfanityFilter([]string{"shit", "damn", "ass"}, ActionMask)
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{content: "shit", want: "****"},
+		{content: "sh1t", want: "****"},
+		{content: "sh!t", want: "****"},
+		{content: "shiiit", want: "******"},
+		{content: "oh shit.", want: "oh ****."},
+		{content: "shit!", want: "****!"},
+		{content: "damn!!", want: "****!!"},
+		{content: "(damn)", want: "(****)"},
+		{content: "what the sh1t!?", want: "what the ****!?"},
+		{content: "as", want: ""},
+		{content: "hello!", want: ""},
+		{content: "wow!!! 100%", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			action, content, _ := filter.Check(&Input{Content: tt.content})
+			if tt.want == "" {
+				if action != ActionAllow {
+					t.Errorf("Check(%q) = %v %q, want allow", tt.content, action, content)
+				}
+				return
+			}
+			if action != ActionMask || content != tt.want {
+				t.Errorf("Check(%q) = %v %q, want mask %q", tt.content, action, content, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package moderation
+
+import (
+	"crypto/sha256"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpamFilter acts when a sender repeats the same content more than
+// repeatLimit times within window, whoever the receivers are.
+type SpamFilter struct {
+	repeatLimit int
+	window      time.Duration
+	action      Action
+
+	mu        sync.Mutex
+	history   map[uint][]sentMessage
+	lastSweep time.Time
+}
+
+type sentMessage struct {
+	hash [sha256.Size]byte
+	at   time.Time
+}
+
+func NewSpamFilter(repeatLimit int, window time.Duration, action Action) *SpamFilter {
+	return &SpamFilter{
+		repeatLimit: repeatLimit,
+		window:      window,
+		action:      action,
+		history:     make(map[uint][]sentMessage),
+	}
+}
+
+func (f *SpamFilter) Name() string {
+	return "spam"
+}
+
+func (f *SpamFilter) Check(input *Input) (Action, string, string) {
+	if f.repeatLimit <= 0 {
+		return ActionAllow, "", ""
+	}
+
+	hash := sha256.Sum256([]byte(strings.ToLower(strings.Join(strings.Fields(input.Content), " "))))
+	now := time.Now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sweep(now)
+
+	recent := f.history[input.SenderID][:0]
+	repeats := 0
+	for _, msg := range f.history[input.SenderID] {
+		if now.Sub(msg.at) > f.window {
+			continue
+		}
+		recent = append(recent, msg)
+		if msg.hash == hash {
+			repeats++
+		}
+	}
+	f.history[input.SenderID] = append(recent, sentMessage{hash: hash, at: now})
+
+	if repeats < f.repeatLimit {
+		return ActionAllow, "", ""
+	}
+	return f.action, input.Content, "message repeated too many times"
+}
+
+func (f *SpamFilter) sweep(now time.Time) {
+	if now.Sub(f.lastSweep) < f.window {
+		return
+	}
+	f.lastSweep = now
+
+	for senderID, messages := range f.history {
+		if len(messages) == 0 || now.Sub(messages[len(messages)-1].at) > f.window {
+			delete(f.history, senderID)
+		}
+	}
+}
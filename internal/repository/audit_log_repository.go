@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *domain.AuditLog) error
+	List(ctx context.Context, action string, limit, offset int) ([]domain.AuditLog, error)
+}
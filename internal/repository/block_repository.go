@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type BlockRepository interface {
+	Create(ctx context.Context, block *domain.UserBlock) error
+	Delete(ctx context.Context, blockerID, blockedID uint) error
+	Exists(ctx context.Context, blockerID, blockedID uint) (bool, error)
+	IsBlockedEitherWay(ctx context.Context, userID, otherUserID uint) (bool, error)
+	ListByBlocker(ctx context.Context, blockerID uint) ([]domain.UserBlock, error)
+}
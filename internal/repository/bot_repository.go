@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type BotRepository interface {
+	// Create inserts the bot together with its user account.
+	Create(ctx context.Context, bot *domain.Bot) error
+	Update(ctx context.Context, bot *domain.Bot) error
+	FindByID(ctx context.Context, id uint) (*domain.Bot, error)
+	FindByUserID(ctx context.Context, userID uint) (*domain.Bot, error)
+	List(ctx context.Context) ([]domain.Bot, error)
+}
+
+type BotTokenRepository interface {
+	Create(ctx context.Context, token *domain.BotToken) error
+	FindByHash(ctx context.Context, hash string) (*domain.BotToken, error)
+	FindByID(ctx context.Context, botID, id uint) (*domain.BotToken, error)
+	ListByBot(ctx context.Context, botID uint) ([]domain.BotToken, error)
+	Revoke(ctx context.Context, id uint, at time.Time) error
+	UpdateLastUsed(ctx context.Context, id uint, at time.Time) error
+}
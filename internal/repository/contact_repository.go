@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type ContactRepository interface {
+	Create(ctx context.Context, contact *domain.Contact) error
+	Update(ctx context.Context, contact *domain.Contact) error
+	Delete(ctx context.Context, id uint) error
+	DeleteBetween(ctx context.Context, userID, otherUserID uint) error
+	FindByID(ctx context.Context, id uint) (*domain.Contact, error)
+	FindBetween(ctx context.Context, userID, otherUserID uint) (*domain.Contact, error)
+	AreContacts(ctx context.Context, userID, otherUserID uint) (bool, error)
+	ListAccepted(ctx context.Context, userID uint) ([]domain.Contact, error)
+	ListIncoming(ctx context.Context, userID uint) ([]domain.Contact, error)
+	ListOutgoing(ctx context.Context, userID uint) ([]domain.Contact, error)
+}
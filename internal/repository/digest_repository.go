@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type DigestRepository interface {
+	FindByUserID(ctx context.Context, userID uint) (*domain.DigestPreferences, error)
+	Save(ctx context.Context, preferences *domain.DigestPreferences) error
+	// FindDue returns users after afterID with digests enabled who have not
+	// been seen since idleBefore and have unread messages received before
+	// idleBefore and after their last digest, if that was at least their
+	// digest interval ago.
+	FindDue(ctx context.Context, afterID uint, idleBefore, dailyBefore, weeklyBefore time.Time, limit int) ([]domain.User, error)
+	// ClaimSend records a digest sent at sentAt, unless one was already
+	// sent after notSentSince, so that only one worker sends it.
+	ClaimSend(ctx context.Context, userID uint, notSentSince, sentAt time.Time) (bool, error)
+}
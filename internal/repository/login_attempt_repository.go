@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// LoginAttemptRepository tracks failed logins and temporary blocks by an
+// arbitrary key (email, IP address).
+type LoginAttemptRepository interface {
+	IncrementFailures(ctx context.Context, key string, window time.Duration) (int64, error)
+	ResetFailures(ctx context.Context, key string) error
+	Block(ctx context.Context, key string, duration time.Duration) error
+	BlockedFor(ctx context.Context, key string) (time.Duration, error)
+	Unblock(ctx context.Context, key string) error
+}
@@ -1,13 +1,29 @@
 package repository
 
-import "github.com/taufiqoo/go-chat/internal/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
 
 type MessageRepository interface {
-	Create(message *domain.Message) error
-	FindByID(id uint) (*domain.Message, error)
-	GetChatHistory(userID, otherUserID uint, limit int) ([]domain.Message, error)
-	MarkAsRead(messageID uint) error
-	GetUnreadCount(userID uint) (int64, error)
-	GetChatList(userID uint) ([]domain.ChatListItem, error)
-	GetUnreadCountByUser(currentUserID, otherUserID uint) (int64, error)
+	Create(ctx context.Context, message *domain.Message) error
+	// CreateWithEvents inserts the message and the outbox events built from
+	// it (with its ID set) in one transaction.
+	CreateWithEvents(ctx context.Context, message *domain.Message, events func(*domain.Message) ([]domain.OutboxEvent, error)) ([]domain.OutboxEvent, error)
+	FindByID(ctx context.Context, id uint) (*domain.Message, error)
+	GetChatHistory(ctx context.Context, userID, otherUserID uint, limit int) ([]domain.Message, error)
+	// MarkAsReadWithEvents marks an unread message as read and inserts the
+	// outbox events in one transaction. It returns no events if the message
+	// was already read.
+	MarkAsReadWithEvents(ctx context.Context, messageID uint, events []domain.OutboxEvent) ([]domain.OutboxEvent, error)
+	GetUnreadCount(ctx context.Context, userID uint) (int64, error)
+	GetChatList(ctx context.Context, userID uint) ([]domain.ChatListItem, error)
+	GetUnreadCountByUser(ctx context.Context, currentUserID, otherUserID uint) (int64, error)
+	Count(ctx context.Context) (int64, error)
+	CountSince(ctx context.Context, since time.Time) (int64, error)
+	HasConversation(ctx context.Context, userID, otherUserID uint) (bool, error)
+	HasSent(ctx context.Context, senderID, receiverID uint) (bool, error)
+	Delete(ctx context.Context, id uint) error
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type ModerationFlagRepository interface {
+	Create(ctx context.Context, flag *domain.ModerationFlag) error
+	Update(ctx context.Context, flag *domain.ModerationFlag) error
+	FindByID(ctx context.Context, id uint) (*domain.ModerationFlag, error)
+	List(ctx context.Context, status string, limit, offset int) ([]domain.ModerationFlag, int64, error)
+	UpdateStatusByMessage(ctx context.Context, messageID uint, status string, reviewerID uint) error
+}
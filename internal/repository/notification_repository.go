@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type DeviceTokenRepository interface {
+	// Upsert stores the token, moving it to the given user if another
+	// account registered it before.
+	Upsert(ctx context.Context, device *domain.DeviceToken) error
+	FindByUser(ctx context.Context, userID uint) ([]domain.DeviceToken, error)
+	Delete(ctx context.Context, userID, id uint) error
+	DeleteByToken(ctx context.Context, token string) error
+}
+
+type ConversationMuteRepository interface {
+	Upsert(ctx context.Context, mute *domain.ConversationMute) error
+	Delete(ctx context.Context, userID, otherUserID uint) error
+	FindByUser(ctx context.Context, userID uint, now time.Time) ([]domain.ConversationMute, error)
+	IsMuted(ctx context.Context, userID, otherUserID uint, now time.Time) (bool, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type OutboxRepository interface {
+	// ClaimDue locks up to limit unprocessed events whose lock has expired,
+	// so that only one relay publishes each of them.
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.OutboxEvent, error)
+	MarkProcessed(ctx context.Context, ids []uint) error
+	// RecordFailure keeps the event locked until retryAt.
+	RecordFailure(ctx context.Context, id uint, lastError string, retryAt time.Time) error
+	DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error)
+}
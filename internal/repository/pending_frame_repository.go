@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"time"
+)
+
+// PendingFrameRepository holds WebSocket frames that could not be delivered
+// to a slow client, so they can be replayed when the user reconnects.
+type PendingFrameRepository interface {
+	Push(ctx context.Context, userID uint, frame []byte, maxFrames int, ttl time.Duration) error
+	PopAll(ctx context.Context, userID uint) ([][]byte, error)
+}
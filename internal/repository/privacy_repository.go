@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type PrivacyRepository interface {
+	FindByUserID(ctx context.Context, userID uint) (*domain.PrivacySettings, error)
+	Save(ctx context.Context, settings *domain.PrivacySettings) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type ReportRepository interface {
+	Create(ctx context.Context, report *domain.Report) error
+	Update(ctx context.Context, report *domain.Report) error
+	FindByID(ctx context.Context, id uint) (*domain.Report, error)
+	List(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, int64, error)
+	HasOpenReport(ctx context.Context, reporterID uint, targetType string, targetID uint) (bool, error)
+}
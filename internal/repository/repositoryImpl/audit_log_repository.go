@@ -0,0 +1,30 @@
+package repositoryImpl
+
+import (
+	"context"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) repository.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, entry *domain.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *auditLogRepository) List(ctx context.Context, action string, limit, offset int) ([]domain.AuditLog, error) {
+	var entries []domain.AuditLog
+	query := r.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Offset(offset)
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	err := query.Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,51 @@
+package repositoryImpl
+
+import (
+	"context"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRepository(db *gorm.DB) repository.BlockRepository {
+	return &blockRepository{db: db}
+}
+
+func (r *blockRepository) Create(ctx context.Context, block *domain.UserBlock) error {
+	return r.db.WithContext(ctx).Create(block).Error
+}
+
+func (r *blockRepository) Delete(ctx context.Context, blockerID, blockedID uint) error {
+	return r.db.WithContext(ctx).Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&domain.UserBlock{}).Error
+}
+
+func (r *blockRepository) Exists(ctx context.Context, blockerID, blockedID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.UserBlock{}).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *blockRepository) IsBlockedEitherWay(ctx context.Context, userID, otherUserID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)",
+			userID, otherUserID, otherUserID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *blockRepository) ListByBlocker(ctx context.Context, blockerID uint) ([]domain.UserBlock, error) {
+	var blocks []domain.UserBlock
+	err := r.db.WithContext(ctx).Preload("Blocked").
+		Where("blocker_id = ?", blockerID).
+		Order("created_at DESC").
+		Find(&blocks).Error
+	return blocks, err
+}
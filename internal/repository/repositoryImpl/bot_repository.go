@@ -0,0 +1,96 @@
+package repositoryImpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type botRepository struct {
+	db *gorm.DB
+}
+
+func NewBotRepository(db *gorm.DB) repository.BotRepository {
+	return &botRepository{db: db}
+}
+
+func (r *botRepository) Create(ctx context.Context, bot *domain.Bot) error {
+	// GORM inserts the User association first, in the same transaction.
+	return r.db.WithContext(ctx).Create(bot).Error
+}
+
+func (r *botRepository) Update(ctx context.Context, bot *domain.Bot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&bot.User).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User", "Webhook").Save(bot).Error
+	})
+}
+
+func (r *botRepository) FindByID(ctx context.Context, id uint) (*domain.Bot, error) {
+	var bot domain.Bot
+	if err := r.db.WithContext(ctx).Preload("User").Preload("Webhook").First(&bot, id).Error; err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
+func (r *botRepository) FindByUserID(ctx context.Context, userID uint) (*domain.Bot, error) {
+	var bot domain.Bot
+	if err := r.db.WithContext(ctx).Preload("User").Where("user_id = ?", userID).First(&bot).Error; err != nil {
+		return nil, err
+	}
+	return &bot, nil
+}
+
+func (r *botRepository) List(ctx context.Context) ([]domain.Bot, error) {
+	var bots []domain.Bot
+	err := r.db.WithContext(ctx).Preload("User").Preload("Webhook").Order("created_at ASC").Find(&bots).Error
+	return bots, err
+}
+
+type botTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewBotTokenRepository(db *gorm.DB) repository.BotTokenRepository {
+	return &botTokenRepository{db: db}
+}
+
+func (r *botTokenRepository) Create(ctx context.Context, token *domain.BotToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *botTokenRepository) FindByHash(ctx context.Context, hash string) (*domain.BotToken, error) {
+	var token domain.BotToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *botTokenRepository) FindByID(ctx context.Context, botID, id uint) (*domain.BotToken, error) {
+	var token domain.BotToken
+	if err := r.db.WithContext(ctx).Where("bot_id = ?", botID).First(&token, id).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *botTokenRepository) ListByBot(ctx context.Context, botID uint) ([]domain.BotToken, error) {
+	var tokens []domain.BotToken
+	err := r.db.WithContext(ctx).Where("bot_id = ?", botID).Order("created_at ASC").Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *botTokenRepository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.BotToken{}).Where("id = ?", id).Update("revoked_at", at).Error
+}
+
+func (r *botTokenRepository) UpdateLastUsed(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.BotToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
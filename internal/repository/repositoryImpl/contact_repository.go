@@ -0,0 +1,99 @@
+package repositoryImpl
+
+import (
+	"context"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type contactRepository struct {
+	db *gorm.DB
+}
+
+func NewContactRepository(db *gorm.DB) repository.ContactRepository {
+	return &contactRepository{db: db}
+}
+
+func (r *contactRepository) Create(ctx context.Context, contact *domain.Contact) error {
+	return r.db.WithContext(ctx).Create(contact).Error
+}
+
+func (r *contactRepository) Update(ctx context.Context, contact *domain.Contact) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(contact).Error
+}
+
+func (r *contactRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.Contact{}, id).Error
+}
+
+func (r *contactRepository) DeleteBetween(ctx context.Context, userID, otherUserID uint) error {
+	return r.db.WithContext(ctx).
+		Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)",
+			userID, otherUserID, otherUserID, userID).
+		Delete(&domain.Contact{}).Error
+}
+
+func (r *contactRepository) FindByID(ctx context.Context, id uint) (*domain.Contact, error) {
+	var contact domain.Contact
+	err := r.db.WithContext(ctx).Preload("Requester").Preload("Addressee").First(&contact, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+func (r *contactRepository) FindBetween(ctx context.Context, userID, otherUserID uint) (*domain.Contact, error) {
+	var contact domain.Contact
+	err := r.db.WithContext(ctx).
+		Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)",
+			userID, otherUserID, otherUserID, userID).
+		First(&contact).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+func (r *contactRepository) AreContacts(ctx context.Context, userID, otherUserID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Contact{}).
+		Where("((requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)) AND status = ?",
+			userID, otherUserID, otherUserID, userID, domain.ContactStatusAccepted).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *contactRepository) ListAccepted(ctx context.Context, userID uint) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	err := r.db.WithContext(ctx).
+		Preload("Requester").
+		Preload("Addressee").
+		Where("(requester_id = ? OR addressee_id = ?) AND status = ?", userID, userID, domain.ContactStatusAccepted).
+		Order("updated_at DESC").
+		Find(&contacts).Error
+	return contacts, err
+}
+
+func (r *contactRepository) ListIncoming(ctx context.Context, userID uint) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	err := r.db.WithContext(ctx).
+		Preload("Requester").
+		Preload("Addressee").
+		Where("addressee_id = ? AND status = ?", userID, domain.ContactStatusPending).
+		Order("created_at DESC").
+		Find(&contacts).Error
+	return contacts, err
+}
+
+func (r *contactRepository) ListOutgoing(ctx context.Context, userID uint) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	err := r.db.WithContext(ctx).
+		Preload("Requester").
+		Preload("Addressee").
+		Where("requester_id = ? AND status = ?", userID, domain.ContactStatusPending).
+		Order("created_at DESC").
+		Find(&contacts).Error
+	return contacts, err
+}
@@ -0,0 +1,78 @@
+package repositoryImpl
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type digestRepository struct {
+	db *gorm.DB
+}
+
+func NewDigestRepository(db *gorm.DB) repository.DigestRepository {
+	return &digestRepository{db: db}
+}
+
+func (r *digestRepository) FindByUserID(ctx context.Context, userID uint) (*domain.DigestPreferences, error) {
+	var preferences domain.DigestPreferences
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&preferences).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultDigestPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+func (r *digestRepository) Save(ctx context.Context, preferences *domain.DigestPreferences) error {
+	return r.db.WithContext(ctx).Save(preferences).Error
+}
+
+func (r *digestRepository) FindDue(ctx context.Context, afterID uint, idleBefore, dailyBefore, weeklyBefore time.Time, limit int) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT u.*
+		FROM users u
+		LEFT JOIN digest_preferences p ON p.user_id = u.id
+		WHERE u.id > ?
+			AND u.is_bot = FALSE
+			AND u.suspended_at IS NULL
+			AND (u.last_seen_at IS NULL OR u.last_seen_at < ?)
+			AND (p.user_id IS NULL OR p.enabled = TRUE)
+			AND (p.last_sent_at IS NULL OR p.last_sent_at < CASE WHEN p.frequency = ? THEN ? ELSE ? END)
+			AND EXISTS (
+				SELECT 1 FROM messages m
+				WHERE m.receiver_id = u.id
+					AND m.is_read = FALSE
+					AND m.created_at < ?
+					AND (p.last_sent_at IS NULL OR m.created_at > p.last_sent_at)
+			)
+		ORDER BY u.id
+		LIMIT ?
+	`, afterID, idleBefore, domain.DigestFrequencyWeekly, weeklyBefore, dailyBefore, idleBefore, limit).Scan(&users).Error
+	return users, err
+}
+
+func (r *digestRepository) ClaimSend(ctx context.Context, userID uint, notSentSince, sentAt time.Time) (bool, error) {
+	claimed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(domain.DefaultDigestPreferences(userID)).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&domain.DigestPreferences{}).
+			Where("user_id = ? AND (last_sent_at IS NULL OR last_sent_at < ?)", userID, notSentSince).
+			Update("last_sent_at", sentAt)
+		claimed = result.RowsAffected == 1
+		return result.Error
+	})
+	return claimed, err
+}
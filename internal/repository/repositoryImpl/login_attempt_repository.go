@@ -0,0 +1,195 @@
+package repositoryImpl
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+const (
+	loginFailuresPrefix = "login:failures:"
+	loginBlockPrefix    = "login:block:"
+)
+
+// incrementFailuresScript counts a failure and starts the window on the
+// first one in the same step, so a counter can never be left without a TTL.
+// A counter that has no TTL, from before the script was used, gets one too.
+var incrementFailuresScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 or redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// loginAttemptRepository keeps login attempt state in Redis so it is shared
+// between instances. When Redis is not configured or a command fails, the
+// in-memory store is used instead so protection is never switched off.
+type loginAttemptRepository struct {
+	redisClient *redis.Client
+	memory      *memoryLoginAttempts
+}
+
+func NewLoginAttemptRepository(redisClient *redis.Client) repository.LoginAttemptRepository {
+	return &loginAttemptRepository{
+		redisClient: redisClient,
+		memory:      newMemoryLoginAttempts(),
+	}
+}
+
+func (r *loginAttemptRepository) IncrementFailures(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if r.redisClient == nil {
+		return r.memory.incrementFailures(key, window), nil
+	}
+
+	redisKey := []string{loginFailuresPrefix + key}
+
+	count, err := incrementFailuresScript.Run(ctx, r.redisClient, redisKey, window.Milliseconds()).Int64()
+	if err != nil {
+		slog.WarnContext(ctx, "Login attempt tracking falling back to memory", "error", err)
+		return r.memory.incrementFailures(key, window), nil
+	}
+
+	return count, nil
+}
+
+func (r *loginAttemptRepository) ResetFailures(ctx context.Context, key string) error {
+	r.memory.resetFailures(key)
+	if r.redisClient == nil {
+		return nil
+	}
+	return r.redisClient.Del(ctx, loginFailuresPrefix+key).Err()
+}
+
+func (r *loginAttemptRepository) Block(ctx context.Context, key string, duration time.Duration) error {
+	r.memory.block(key, duration)
+	if r.redisClient == nil {
+		return nil
+	}
+	return r.redisClient.Set(ctx, loginBlockPrefix+key, "1", duration).Err()
+}
+
+func (r *loginAttemptRepository) BlockedFor(ctx context.Context, key string) (time.Duration, error) {
+	remaining := r.memory.blockedFor(key)
+	if r.redisClient == nil {
+		return remaining, nil
+	}
+
+	ttl, err := r.redisClient.PTTL(ctx, loginBlockPrefix+key).Result()
+	if err != nil {
+		slog.WarnContext(ctx, "Login block lookup falling back to memory", "error", err)
+		return remaining, nil
+	}
+
+	if ttl > remaining {
+		remaining = ttl
+	}
+	return remaining, nil
+}
+
+func (r *loginAttemptRepository) Unblock(ctx context.Context, key string) error {
+	r.memory.unblock(key)
+	if r.redisClient == nil {
+		return nil
+	}
+	return r.redisClient.Del(ctx, loginBlockPrefix+key, loginFailuresPrefix+key).Err()
+}
+
+type memoryLoginAttempt struct {
+	failures     int64
+	windowEnd    time.Time
+	blockedUntil time.Time
+}
+
+type memoryLoginAttempts struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryLoginAttempt
+	lastSweep time.Time
+}
+
+func newMemoryLoginAttempts() *memoryLoginAttempts {
+	return &memoryLoginAttempts{entries: make(map[string]*memoryLoginAttempt)}
+}
+
+func (m *memoryLoginAttempts) incrementFailures(key string, window time.Duration) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	entry := m.entry(key)
+	if now.After(entry.windowEnd) {
+		entry.failures = 0
+		entry.windowEnd = now.Add(window)
+	}
+	entry.failures++
+
+	return entry.failures
+}
+
+func (m *memoryLoginAttempts) resetFailures(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry, ok := m.entries[key]; ok {
+		entry.failures = 0
+	}
+}
+
+func (m *memoryLoginAttempts) block(key string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entry(key).blockedUntil = time.Now().Add(duration)
+}
+
+func (m *memoryLoginAttempts) blockedFor(key string) time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return 0
+	}
+
+	if remaining := time.Until(entry.blockedUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+func (m *memoryLoginAttempts) unblock(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+}
+
+func (m *memoryLoginAttempts) entry(key string) *memoryLoginAttempt {
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &memoryLoginAttempt{}
+		m.entries[key] = entry
+	}
+	return entry
+}
+
+// sweep drops expired entries at most once a minute so the map cannot grow
+// without bound under a spraying attack.
+func (m *memoryLoginAttempts) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	m.lastSweep = now
+
+	for key, entry := range m.entries {
+		if now.After(entry.windowEnd) && now.After(entry.blockedUntil) {
+			delete(m.entries, key)
+		}
+	}
+}
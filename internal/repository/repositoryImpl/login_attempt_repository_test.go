@@ -0,0 +1,120 @@
+package repositoryImpl
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// testRedisClient connects to REDIS_TEST_ADDR and skips the test when it is
+// not set, so the suite still runs without a Redis server.
+func testRedisClient(t *testing.T) *redis.Client {
+	t.Helper()
+
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("ping %s: %v", addr, err)
+	}
+	return client
+}
+
+func TestIncrementFailuresScript(t *testing.T) {
+	ctx := context.Background()
+	client := testRedisClient(t)
+	repo := NewLoginAttemptRepository(client)
+	const window = time.Minute
+
+	tests := []struct {
+		name string
+		// setup prepares the counter before the increment under test.
+		setup     func(key string)
+		wantCount int64
+	}{
+		{name: "first failure starts the window", setup: func(string) {}, wantCount: 1},
+		{
+			name: "later failure keeps the window",
+			setup: func(key string) {
+				client.Set(ctx, key, 2, 30*time.Second)
+			},
+			wantCount: 3,
+		},
+		{
+			name: "counter without a TTL gets one",
+			setup: func(key string) {
+				client.Set(ctx, key, 4, 0)
+			},
+			wantCount: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := "test:" + t.Name()
+			redisKey := loginFailuresPrefix + key
+			client.Del(ctx, redisKey)
+			t.Cleanup(func() { client.Del(ctx, redisKey) })
+			tt.setup(redisKey)
+
+			count, err := repo.IncrementFailures(ctx, key, window)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+
+			ttl, err := client.PTTL(ctx, redisKey).Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ttl <= 0 || ttl > window {
+				t.Errorf("TTL = %v, want within the %v window", ttl, window)
+			}
+		})
+	}
+}
+
+func TestMemoryLoginAttempts(t *testing.T) {
+	ctx := context.Background()
+	repo := NewLoginAttemptRepository(nil)
+
+	for want := int64(1); want <= 3; want++ {
+		count, err := repo.IncrementFailures(ctx, "key", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Fatalf("count = %d, want %d", count, want)
+		}
+	}
+
+	// An expired window starts counting again.
+	if count, _ := repo.IncrementFailures(ctx, "expired", 0); count != 1 {
+		t.Fatalf("count = %d, want 1", count)
+	}
+	if count, _ := repo.IncrementFailures(ctx, "expired", 0); count != 1 {
+		t.Errorf("count after the window = %d, want 1", count)
+	}
+
+	if err := repo.Block(ctx, "key", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, _ := repo.BlockedFor(ctx, "key"); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("BlockedFor() = %v, want within a minute", remaining)
+	}
+	if err := repo.Unblock(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, _ := repo.BlockedFor(ctx, "key"); remaining != 0 {
+		t.Errorf("BlockedFor() after Unblock = %v, want 0", remaining)
+	}
+}
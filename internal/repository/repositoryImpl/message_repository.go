@@ -1,6 +1,9 @@
 package repositoryImpl
 
 import (
+	"context"
+	"time"
+
 	"github.com/taufiqoo/go-chat/internal/domain"
 	"github.com/taufiqoo/go-chat/internal/repository"
 	"gorm.io/gorm"
@@ -14,22 +17,42 @@ func NewMessageRepository(db *gorm.DB) repository.MessageRepository {
 	return &messageRepository{db: db}
 }
 
-func (r *messageRepository) Create(message *domain.Message) error {
-	return r.db.Create(message).Error
+func (r *messageRepository) Create(ctx context.Context, message *domain.Message) error {
+	return r.db.WithContext(ctx).Create(message).Error
+}
+
+func (r *messageRepository) CreateWithEvents(ctx context.Context, message *domain.Message, events func(*domain.Message) ([]domain.OutboxEvent, error)) ([]domain.OutboxEvent, error) {
+	var outbox []domain.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		var err error
+		outbox, err = events(message)
+		if err != nil || len(outbox) == 0 {
+			return err
+		}
+		return tx.Create(&outbox).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outbox, nil
 }
 
-func (r *messageRepository) FindByID(id uint) (*domain.Message, error) {
+func (r *messageRepository) FindByID(ctx context.Context, id uint) (*domain.Message, error) {
 	var message domain.Message
-	err := r.db.Preload("Sender").Preload("Receiver").First(&message, id).Error
+	err := r.db.WithContext(ctx).Preload("Sender").Preload("Receiver").First(&message, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &message, nil
 }
 
-func (r *messageRepository) GetChatHistory(userID, otherUserID uint, limit int) ([]domain.Message, error) {
+func (r *messageRepository) GetChatHistory(ctx context.Context, userID, otherUserID uint, limit int) ([]domain.Message, error) {
 	var messages []domain.Message
-	err := r.db.
+	err := r.db.WithContext(ctx).
 		Preload("Sender").
 		Preload("Receiver").
 		Where("(sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?)",
@@ -45,10 +68,10 @@ func (r *messageRepository) GetChatHistory(userID, otherUserID uint, limit int)
 	return messages, err
 }
 
-func (r *messageRepository) GetChatList(userID uint) ([]domain.ChatListItem, error) {
+func (r *messageRepository) GetChatList(ctx context.Context, userID uint) ([]domain.ChatListItem, error) {
 	var chatList []domain.ChatListItem
 
-	err := r.db.Raw(`
+	err := r.db.WithContext(ctx).Raw(`
 		SELECT 
 			u.id AS user_id,
 			u.fullname,
@@ -82,30 +105,80 @@ func (r *messageRepository) GetChatList(userID uint) ([]domain.ChatListItem, err
 	}
 
 	for i := range chatList {
-		count, _ := r.GetUnreadCountByUser(userID, chatList[i].UserID)
+		count, _ := r.GetUnreadCountByUser(ctx, userID, chatList[i].UserID)
 		chatList[i].UnreadCount = int(count)
 	}
 
 	return chatList, nil
 }
 
-func (r *messageRepository) MarkAsRead(messageID uint) error {
-	return r.db.Model(&domain.Message{}).Where("id = ?", messageID).Update("is_read", true).Error
+func (r *messageRepository) MarkAsReadWithEvents(ctx context.Context, messageID uint, events []domain.OutboxEvent) ([]domain.OutboxEvent, error) {
+	var outbox []domain.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Message{}).
+			Where("id = ? AND is_read = ?", messageID, false).
+			Update("is_read", true)
+		if result.Error != nil || result.RowsAffected == 0 || len(events) == 0 {
+			return result.Error
+		}
+
+		outbox = events
+		return tx.Create(&outbox).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outbox, nil
 }
 
-func (r *messageRepository) GetUnreadCount(userID uint) (int64, error) {
+func (r *messageRepository) GetUnreadCount(ctx context.Context, userID uint) (int64, error) {
 	var count int64
-	err := r.db.Model(&domain.Message{}).
+	err := r.db.WithContext(ctx).Model(&domain.Message{}).
 		Where("receiver_id = ? AND is_read = ?", userID, false).
 		Count(&count).Error
 	return count, err
 }
 
-func (r *messageRepository) GetUnreadCountByUser(currentUserID, otherUserID uint) (int64, error) {
+func (r *messageRepository) GetUnreadCountByUser(ctx context.Context, currentUserID, otherUserID uint) (int64, error) {
 	var count int64
-	err := r.db.Model(&domain.Message{}).
+	err := r.db.WithContext(ctx).Model(&domain.Message{}).
 		Where("sender_id = ? AND receiver_id = ? AND is_read = ?",
 			otherUserID, currentUserID, false).
 		Count(&count).Error
 	return count, err
 }
+
+func (r *messageRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Message{}).Count(&count).Error
+	return count, err
+}
+
+func (r *messageRepository) CountSince(ctx context.Context, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Message{}).Where("created_at >= ?", since).Count(&count).Error
+	return count, err
+}
+
+func (r *messageRepository) HasConversation(ctx context.Context, userID, otherUserID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Message{}).
+		Where("(sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?)",
+			userID, otherUserID, otherUserID, userID).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *messageRepository) HasSent(ctx context.Context, senderID, receiverID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Message{}).
+		Where("sender_id = ? AND receiver_id = ?", senderID, receiverID).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *messageRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.Message{}, id).Error
+}
@@ -0,0 +1,63 @@
+package repositoryImpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type moderationFlagRepository struct {
+	db *gorm.DB
+}
+
+func NewModerationFlagRepository(db *gorm.DB) repository.ModerationFlagRepository {
+	return &moderationFlagRepository{db: db}
+}
+
+func (r *moderationFlagRepository) Create(ctx context.Context, flag *domain.ModerationFlag) error {
+	return r.db.WithContext(ctx).Create(flag).Error
+}
+
+func (r *moderationFlagRepository) Update(ctx context.Context, flag *domain.ModerationFlag) error {
+	return r.db.WithContext(ctx).Save(flag).Error
+}
+
+func (r *moderationFlagRepository) FindByID(ctx context.Context, id uint) (*domain.ModerationFlag, error) {
+	var flag domain.ModerationFlag
+	if err := r.db.WithContext(ctx).First(&flag, id).Error; err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (r *moderationFlagRepository) List(ctx context.Context, status string, limit, offset int) ([]domain.ModerationFlag, int64, error) {
+	var flags []domain.ModerationFlag
+	var total int64
+
+	q := r.db.WithContext(ctx).Model(&domain.ModerationFlag{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Order("created_at ASC").Limit(limit).Offset(offset).Find(&flags).Error
+	return flags, total, err
+}
+
+// UpdateStatusByMessage resolves every pending flag raised on the message,
+// since a message can be flagged by several filters.
+func (r *moderationFlagRepository) UpdateStatusByMessage(ctx context.Context, messageID uint, status string, reviewerID uint) error {
+	return r.db.WithContext(ctx).Model(&domain.ModerationFlag{}).
+		Where("message_id = ? AND status = ?", messageID, domain.FlagStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewerID,
+			"reviewed_at": time.Now(),
+		}).Error
+}
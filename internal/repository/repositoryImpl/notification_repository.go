@@ -0,0 +1,88 @@
+package repositoryImpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type deviceTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceTokenRepository(db *gorm.DB) repository.DeviceTokenRepository {
+	return &deviceTokenRepository{db: db}
+}
+
+func (r *deviceTokenRepository) Upsert(ctx context.Context, device *domain.DeviceToken) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "device_name", "updated_at"}),
+	}).Create(device).Error
+	if err != nil {
+		return err
+	}
+	// The insert may have turned into an update of an existing row.
+	return r.db.WithContext(ctx).Where("token = ?", device.Token).First(device).Error
+}
+
+func (r *deviceTokenRepository) FindByUser(ctx context.Context, userID uint) ([]domain.DeviceToken, error) {
+	var devices []domain.DeviceToken
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&devices).Error
+	return devices, err
+}
+
+func (r *deviceTokenRepository) Delete(ctx context.Context, userID, id uint) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&domain.DeviceToken{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *deviceTokenRepository) DeleteByToken(ctx context.Context, token string) error {
+	return r.db.WithContext(ctx).Where("token = ?", token).Delete(&domain.DeviceToken{}).Error
+}
+
+type conversationMuteRepository struct {
+	db *gorm.DB
+}
+
+func NewConversationMuteRepository(db *gorm.DB) repository.ConversationMuteRepository {
+	return &conversationMuteRepository{db: db}
+}
+
+func (r *conversationMuteRepository) Upsert(ctx context.Context, mute *domain.ConversationMute) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "other_user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"muted_until", "updated_at"}),
+	}).Create(mute).Error
+}
+
+func (r *conversationMuteRepository) Delete(ctx context.Context, userID, otherUserID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ? AND other_user_id = ?", userID, otherUserID).Delete(&domain.ConversationMute{}).Error
+}
+
+func (r *conversationMuteRepository) FindByUser(ctx context.Context, userID uint, now time.Time) ([]domain.ConversationMute, error) {
+	var mutes []domain.ConversationMute
+	err := r.db.WithContext(ctx).Preload("OtherUser").
+		Where("user_id = ? AND (muted_until IS NULL OR muted_until > ?)", userID, now).
+		Order("created_at DESC").
+		Find(&mutes).Error
+	return mutes, err
+}
+
+func (r *conversationMuteRepository) IsMuted(ctx context.Context, userID, otherUserID uint, now time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.ConversationMute{}).
+		Where("user_id = ? AND other_user_id = ? AND (muted_until IS NULL OR muted_until > ?)", userID, otherUserID, now).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,76 @@
+package repositoryImpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.OutboxEvent, error) {
+	var candidates []domain.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("processed_at IS NULL").
+		Where("locked_until IS NULL OR locked_until < ?", now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lockedUntil := now.Add(lease)
+	claimed := candidates[:0]
+	for _, event := range candidates {
+		// Another relay may have claimed it since the select.
+		result := r.db.WithContext(ctx).Model(&domain.OutboxEvent{}).
+			Where("id = ? AND processed_at IS NULL AND (locked_until IS NULL OR locked_until < ?)", event.ID, now).
+			Update("locked_until", lockedUntil)
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			event.LockedUntil = &lockedUntil
+			claimed = append(claimed, event)
+		}
+	}
+
+	return claimed, nil
+}
+
+func (r *outboxRepository) MarkProcessed(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&domain.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"processed_at": time.Now(),
+			"locked_until": nil,
+		}).Error
+}
+
+func (r *outboxRepository) RecordFailure(ctx context.Context, id uint, lastError string, retryAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   lastError,
+			"locked_until": retryAt,
+		}).Error
+}
+
+func (r *outboxRepository) DeleteProcessedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("processed_at IS NOT NULL AND processed_at < ?", before).Delete(&domain.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,124 @@
+package repositoryImpl
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+const pendingFramesPrefix = "ws:pending:"
+
+// pendingFrameRepository keeps a capped list per user in Redis, falling back
+// to memory when Redis is not configured or a command fails. Only the most
+// recent maxFrames frames are kept.
+type pendingFrameRepository struct {
+	redisClient *redis.Client
+	memory      *memoryPendingFrames
+}
+
+func NewPendingFrameRepository(redisClient *redis.Client) repository.PendingFrameRepository {
+	return &pendingFrameRepository{
+		redisClient: redisClient,
+		memory:      newMemoryPendingFrames(),
+	}
+}
+
+func (r *pendingFrameRepository) Push(ctx context.Context, userID uint, frame []byte, maxFrames int, ttl time.Duration) error {
+	if r.redisClient == nil {
+		r.memory.push(userID, frame, maxFrames, ttl)
+		return nil
+	}
+
+	key := fmt.Sprintf("%s%d", pendingFramesPrefix, userID)
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.RPush(ctx, key, frame)
+	pipe.LTrim(ctx, key, int64(-maxFrames), -1)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.WarnContext(ctx, "Pending frame queue falling back to memory", "user_id", userID, "error", err)
+		r.memory.push(userID, frame, maxFrames, ttl)
+	}
+
+	return nil
+}
+
+func (r *pendingFrameRepository) PopAll(ctx context.Context, userID uint) ([][]byte, error) {
+	frames := r.memory.popAll(userID)
+	if r.redisClient == nil {
+		return frames, nil
+	}
+
+	key := fmt.Sprintf("%s%d", pendingFramesPrefix, userID)
+
+	pipe := r.redisClient.TxPipeline()
+	lrange := pipe.LRange(ctx, key, 0, -1)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return frames, err
+	}
+
+	for _, frame := range lrange.Val() {
+		frames = append(frames, []byte(frame))
+	}
+	return frames, nil
+}
+
+type memoryPendingQueue struct {
+	frames  [][]byte
+	expires time.Time
+}
+
+type memoryPendingFrames struct {
+	mu     sync.Mutex
+	queues map[uint]*memoryPendingQueue
+}
+
+func newMemoryPendingFrames() *memoryPendingFrames {
+	return &memoryPendingFrames{queues: make(map[uint]*memoryPendingQueue)}
+}
+
+func (m *memoryPendingFrames) push(userID uint, frame []byte, maxFrames int, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, queue := range m.queues {
+		if now.After(queue.expires) {
+			delete(m.queues, id)
+		}
+	}
+
+	queue, ok := m.queues[userID]
+	if !ok {
+		queue = &memoryPendingQueue{}
+		m.queues[userID] = queue
+	}
+
+	queue.frames = append(queue.frames, frame)
+	if len(queue.frames) > maxFrames {
+		queue.frames = queue.frames[len(queue.frames)-maxFrames:]
+	}
+	queue.expires = now.Add(ttl)
+}
+
+func (m *memoryPendingFrames) popAll(userID uint) [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	queue, ok := m.queues[userID]
+	if !ok {
+		return nil
+	}
+	delete(m.queues, userID)
+
+	if time.Now().After(queue.expires) {
+		return nil
+	}
+	return queue.frames
+}
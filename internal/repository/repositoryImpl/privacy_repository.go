@@ -0,0 +1,34 @@
+package repositoryImpl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type privacyRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) repository.PrivacyRepository {
+	return &privacyRepository{db: db}
+}
+
+func (r *privacyRepository) FindByUserID(ctx context.Context, userID uint) (*domain.PrivacySettings, error) {
+	var settings domain.PrivacySettings
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultPrivacySettings(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *privacyRepository) Save(ctx context.Context, settings *domain.PrivacySettings) error {
+	return r.db.WithContext(ctx).Save(settings).Error
+}
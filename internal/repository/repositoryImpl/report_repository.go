@@ -0,0 +1,61 @@
+package repositoryImpl
+
+import (
+	"context"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) repository.ReportRepository {
+	return &reportRepository{db: db}
+}
+
+func (r *reportRepository) Create(ctx context.Context, report *domain.Report) error {
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+func (r *reportRepository) Update(ctx context.Context, report *domain.Report) error {
+	return r.db.WithContext(ctx).Save(report).Error
+}
+
+func (r *reportRepository) FindByID(ctx context.Context, id uint) (*domain.Report, error) {
+	var report domain.Report
+	if err := r.db.WithContext(ctx).First(&report, id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *reportRepository) List(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, int64, error) {
+	var reports []domain.Report
+	var total int64
+
+	q := r.db.WithContext(ctx).Model(&domain.Report{})
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.TargetType != "" {
+		q = q.Where("target_type = ?", filter.TargetType)
+	}
+
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Order("created_at ASC").Limit(filter.Limit).Offset(filter.Offset).Find(&reports).Error
+	return reports, total, err
+}
+
+func (r *reportRepository) HasOpenReport(ctx context.Context, reporterID uint, targetType string, targetID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status IN ?",
+			reporterID, targetType, targetID, []string{domain.ReportStatusOpen, domain.ReportStatusInReview}).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,35 @@
+package repositoryImpl
+
+import (
+	"context"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) repository.UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *userIdentityRepository) FindByProviderSubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) FindByUserID(ctx context.Context, userID uint) ([]domain.UserIdentity, error) {
+	var identities []domain.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&identities).Error
+	return identities, err
+}
@@ -1,6 +1,9 @@
 package repositoryImpl
 
 import (
+	"context"
+	"time"
+
 	"github.com/taufiqoo/go-chat/internal/domain"
 	"github.com/taufiqoo/go-chat/internal/repository"
 	"gorm.io/gorm"
@@ -14,37 +17,124 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *domain.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *userRepository) CreateWithEvents(ctx context.Context, user *domain.User, events func(*domain.User) ([]domain.OutboxEvent, error)) ([]domain.OutboxEvent, error) {
+	return r.CreateWithIdentity(ctx, user, nil, events)
 }
 
-func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
+func (r *userRepository) CreateWithIdentity(ctx context.Context, user *domain.User, identity *domain.UserIdentity, events func(*domain.User) ([]domain.OutboxEvent, error)) ([]domain.OutboxEvent, error) {
+	var outbox []domain.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		if identity != nil {
+			identity.UserID = user.ID
+			if err := tx.Create(identity).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		outbox, err = events(user)
+		if err != nil || len(outbox) == 0 {
+			return err
+		}
+		return tx.Create(&outbox).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outbox, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) FindByID(id uint) (*domain.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.Where("id = ?", id).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) FindByUsername(username string) (*domain.User, error) {
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.Where("username = ?", username).First(&user).Error
+	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetAll() ([]domain.User, error) {
+func (r *userRepository) GetAll(ctx context.Context) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.WithContext(ctx).Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Save(user).Error
+}
+
+func (r *userRepository) Search(ctx context.Context, query string, limit, offset int) ([]domain.User, int64, error) {
+	var users []domain.User
+	var total int64
+
+	q := r.db.WithContext(ctx).Model(&domain.User{})
+	if query != "" {
+		like := "%" + query + "%"
+		q = q.Where("username LIKE ? OR email LIKE ? OR fullname LIKE ?", like, like, like)
+	}
+
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Order("id ASC").Limit(limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+func (r *userRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.User{}).Count(&count).Error
+	return count, err
+}
+
+func (r *userRepository) CountSuspended(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.User{}).Where("suspended_at IS NOT NULL").Count(&count).Error
+	return count, err
+}
+
+// SearchVisible returns users matching the query, leaving out anyone the
+// viewer has blocked or who has blocked the viewer.
+func (r *userRepository) SearchVisible(ctx context.Context, viewerID uint, query string) ([]domain.User, error) {
 	var users []domain.User
-	err := r.db.Find(&users).Error
+
+	q := r.db.WithContext(ctx).
+		Where("id NOT IN (?)", r.db.Model(&domain.UserBlock{}).Select("blocked_id").Where("blocker_id = ?", viewerID)).
+		Where("id NOT IN (?)", r.db.Model(&domain.UserBlock{}).Select("blocker_id").Where("blocked_id = ?", viewerID))
+
+	if query != "" {
+		like := "%" + query + "%"
+		q = q.Where("username LIKE ? OR fullname LIKE ?", like, like)
+	}
+
+	err := q.Order("id ASC").Find(&users).Error
 	return users, err
 }
+
+func (r *userRepository) UpdateLastSeen(ctx context.Context, id uint, lastSeen time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Update("last_seen_at", lastSeen).Error
+}
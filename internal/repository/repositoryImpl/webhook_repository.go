@@ -0,0 +1,151 @@
+package repositoryImpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) repository.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+func (r *webhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	return r.db.WithContext(ctx).Save(webhook).Error
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&domain.WebhookDelivery{}).Select("id").Where("webhook_id = ?", id)
+		if err := tx.Where("delivery_id IN (?)", deliveries).Delete(&domain.WebhookDeliveryAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("webhook_id = ?", id).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Webhook{}, id).Error
+	})
+}
+
+func (r *webhookRepository) FindByID(ctx context.Context, id uint) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	if err := r.db.WithContext(ctx).First(&webhook, id).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) List(ctx context.Context) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	err := r.db.WithContext(ctx).Order("created_at ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) FindActive(ctx context.Context) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	err := r.db.WithContext(ctx).Where("active = ?", true).Find(&webhooks).Error
+	return webhooks, err
+}
+
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) repository.WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+func (r *webhookDeliveryRepository) Create(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&deliveries).Error
+}
+
+func (r *webhookDeliveryRepository) FindByID(ctx context.Context, webhookID, id uint) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.db.WithContext(ctx).Preload("AttemptLogs", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Where("webhook_id = ?", webhookID).First(&delivery, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookDeliveryRepository) List(ctx context.Context, webhookID uint, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, int64, error) {
+	var deliveries []domain.WebhookDelivery
+	var total int64
+
+	q := r.db.WithContext(ctx).Model(&domain.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+func (r *webhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	var candidates []domain.WebhookDelivery
+	err := r.db.WithContext(ctx).Preload("Webhook").
+		Joins("JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id AND webhooks.active = ?", true).
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?", domain.DeliveryStatusPending, now).
+		Where("webhook_deliveries.locked_until IS NULL OR webhook_deliveries.locked_until < ?", now).
+		Order("webhook_deliveries.next_attempt_at ASC").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lockedUntil := now.Add(lease)
+	claimed := candidates[:0]
+	for _, delivery := range candidates {
+		// Another worker may have claimed it since the select.
+		result := r.db.WithContext(ctx).Model(&domain.WebhookDelivery{}).
+			Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", delivery.ID, now).
+			Update("locked_until", lockedUntil)
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			delivery.LockedUntil = &lockedUntil
+			claimed = append(claimed, delivery)
+		}
+	}
+
+	return claimed, nil
+}
+
+func (r *webhookDeliveryRepository) RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookDeliveryAttempt) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(delivery).Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"locked_until":    nil,
+			"last_error":      delivery.LastError,
+			"response_status": delivery.ResponseStatus,
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+	})
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *domain.UserIdentity) error
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error)
+	FindByUserID(ctx context.Context, userID uint) ([]domain.UserIdentity, error)
+}
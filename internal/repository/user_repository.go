@@ -1,11 +1,28 @@
 package repository
 
-import "github.com/taufiqoo/go-chat/internal/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
 
 type UserRepository interface {
-	Create(user *domain.User) error
-	FindByEmail(email string) (*domain.User, error)
-	FindByID(id uint) (*domain.User, error)
-	FindByUsername(username string) (*domain.User, error)
-	GetAll() ([]domain.User, error)
+	Create(ctx context.Context, user *domain.User) error
+	// CreateWithEvents inserts the user and the outbox events built from it
+	// (with its ID set) in one transaction.
+	CreateWithEvents(ctx context.Context, user *domain.User, events func(*domain.User) ([]domain.OutboxEvent, error)) ([]domain.OutboxEvent, error)
+	// CreateWithIdentity is CreateWithEvents that also links the new user to
+	// an external identity in the same transaction.
+	CreateWithIdentity(ctx context.Context, user *domain.User, identity *domain.UserIdentity, events func(*domain.User) ([]domain.OutboxEvent, error)) ([]domain.OutboxEvent, error)
+	FindByEmail(ctx context.Context, email string) (*domain.User, error)
+	FindByID(ctx context.Context, id uint) (*domain.User, error)
+	FindByUsername(ctx context.Context, username string) (*domain.User, error)
+	GetAll(ctx context.Context) ([]domain.User, error)
+	Update(ctx context.Context, user *domain.User) error
+	Search(ctx context.Context, query string, limit, offset int) ([]domain.User, int64, error)
+	Count(ctx context.Context) (int64, error)
+	CountSuspended(ctx context.Context) (int64, error)
+	SearchVisible(ctx context.Context, viewerID uint, query string) ([]domain.User, error)
+	UpdateLastSeen(ctx context.Context, id uint, lastSeen time.Time) error
 }
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	Update(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, id uint) error
+	FindByID(ctx context.Context, id uint) (*domain.Webhook, error)
+	List(ctx context.Context) ([]domain.Webhook, error)
+	FindActive(ctx context.Context) ([]domain.Webhook, error)
+}
+
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, deliveries []domain.WebhookDelivery) error
+	FindByID(ctx context.Context, webhookID, id uint) (*domain.WebhookDelivery, error)
+	List(ctx context.Context, webhookID uint, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, int64, error)
+	// ClaimDue locks up to limit pending deliveries of active webhooks that
+	// are due, so that only one worker sends each of them.
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	// RecordAttempt stores the attempt log and the updated delivery, and
+	// releases the lock.
+	RecordAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookDeliveryAttempt) error
+}
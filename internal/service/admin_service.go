@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+// ConnectionManager exposes the live connection state needed by the admin
+// API. It is implemented by the WebSocket hub.
+type ConnectionManager interface {
+	ConnectedClients() int
+	ConnectedUsers() int
+	DeliveryStats() domain.DeliveryStats
+	DisconnectUser(userID uint, reason string)
+}
+
+// EventCounter reports how many domain events were published, by type. It is
+// implemented by eventbus.Counter.
+type EventCounter interface {
+	Counts() map[string]int64
+}
+
+var (
+	// ErrInsufficientRole is returned when the actor's role is not higher
+	// than the role of the account they try to act on.
+	ErrInsufficientRole = errors.New("you cannot act on an account with an equal or higher role")
+	// ErrOwnAccount is returned when an admin action targets the actor's own
+	// account.
+	ErrOwnAccount = errors.New("you cannot perform this action on your own account")
+	// ErrAlreadySuspended is returned when suspending a suspended account.
+	ErrAlreadySuspended = errors.New("user already suspended")
+)
+
+// loadActionTarget loads the account an admin action is about, after checking
+// that it is not the actor's own and that the actor outranks it.
+func loadActionTarget(ctx context.Context, userRepo repository.UserRepository, actorID, userID uint) (*domain.User, error) {
+	if actorID == userID {
+		return nil, ErrOwnAccount
+	}
+
+	actor, err := userRepo.FindByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !actor.Outranks(user) {
+		return nil, ErrInsufficientRole
+	}
+	return user, nil
+}
+
+type AdminService interface {
+	ListUsers(ctx context.Context, query string, limit, offset int) ([]domain.User, int64, error)
+	SuspendUser(ctx context.Context, actorID, userID uint, reason, clientIP string) error
+	UnsuspendUser(ctx context.Context, actorID, userID uint, clientIP string) error
+	UpdateRole(ctx context.Context, actorID, userID uint, role, clientIP string) error
+	GetStats(ctx context.Context) (*domain.SystemStats, error)
+}
+
+type adminService struct {
+	userRepo    repository.UserRepository
+	messageRepo repository.MessageRepository
+	auditRepo   repository.AuditLogRepository
+	connections ConnectionManager
+	events      EventCounter
+}
+
+func NewAdminService(
+	userRepo repository.UserRepository,
+	messageRepo repository.MessageRepository,
+	auditRepo repository.AuditLogRepository,
+	connections ConnectionManager,
+	events EventCounter,
+) AdminService {
+	return &adminService{
+		userRepo:    userRepo,
+		messageRepo: messageRepo,
+		auditRepo:   auditRepo,
+		connections: connections,
+		events:      events,
+	}
+}
+
+func (s *adminService) ListUsers(ctx context.Context, query string, limit, offset int) ([]domain.User, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.userRepo.Search(ctx, query, limit, offset)
+}
+
+func (s *adminService) SuspendUser(ctx context.Context, actorID, userID uint, reason, clientIP string) error {
+	user, err := loadActionTarget(ctx, s.userRepo, actorID, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsSuspended() {
+		return ErrAlreadySuspended
+	}
+
+	now := time.Now()
+	user.SuspendedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.connections.DisconnectUser(user.ID, "account suspended")
+
+	recordAudit(ctx, s.auditRepo, &domain.AuditLog{
+		ActorID:    &actorID,
+		Action:     domain.AuditActionUserSuspended,
+		TargetType: "user",
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+		IP:         clientIP,
+	}, map[string]interface{}{"reason": reason})
+
+	return nil
+}
+
+func (s *adminService) UnsuspendUser(ctx context.Context, actorID, userID uint, clientIP string) error {
+	user, err := loadActionTarget(ctx, s.userRepo, actorID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsSuspended() {
+		return errors.New("user is not suspended")
+	}
+
+	user.SuspendedAt = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditRepo, &domain.AuditLog{
+		ActorID:    &actorID,
+		Action:     domain.AuditActionUserUnsuspended,
+		TargetType: "user",
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+		IP:         clientIP,
+	}, nil)
+
+	return nil
+}
+
+func (s *adminService) UpdateRole(ctx context.Context, actorID, userID uint, role, clientIP string) error {
+	user, err := loadActionTarget(ctx, s.userRepo, actorID, userID)
+	if err != nil {
+		return err
+	}
+
+	previous := user.Role
+	user.Role = role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditRepo, &domain.AuditLog{
+		ActorID:    &actorID,
+		Action:     domain.AuditActionRoleChanged,
+		TargetType: "user",
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+		IP:         clientIP,
+	}, map[string]interface{}{"from": previous, "to": role})
+
+	return nil
+}
+
+func (s *adminService) GetStats(ctx context.Context) (*domain.SystemStats, error) {
+	totalUsers, err := s.userRepo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	suspendedUsers, err := s.userRepo.CountSuspended(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totalMessages, err := s.messageRepo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	lastHour, err := s.messageRepo.CountSince(ctx, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	last24h, err := s.messageRepo.CountSince(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SystemStats{
+		TotalUsers:       totalUsers,
+		SuspendedUsers:   suspendedUsers,
+		ConnectedClients: s.connections.ConnectedClients(),
+		ConnectedUsers:   s.connections.ConnectedUsers(),
+		TotalMessages:    totalMessages,
+		MessagesLastHour: lastHour,
+		MessagesLast24h:  last24h,
+		Delivery:         s.connections.DeliveryStats(),
+		Events:           s.events.Counts(),
+	}, nil
+}
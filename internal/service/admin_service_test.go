@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+type fakeConnections struct {
+	ConnectionManager
+	disconnected []uint
+}
+
+func (f *fakeConnections) DisconnectUser(userID uint, reason string) {
+	f.disconnected = append(f.disconnected, userID)
+}
+
+type fakeAuditRepo struct {
+	repository.AuditLogRepository
+	entries []*domain.AuditLog
+}
+
+func (r *fakeAuditRepo) Create(ctx context.Context, entry *domain.AuditLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func TestSuspendUserRequiresHigherRole(t *testing.T) {
+	tests := []struct {
+		name      string
+		actor     string
+		target    string
+		wantError error
+	}{
+		{name: "admin suspends user", actor: domain.RoleAdmin, target: domain.RoleUser},
+		{name: "admin suspends moderator", actor: domain.RoleAdmin, target: domain.RoleModerator},
+		{name: "moderator suspends user", actor: domain.RoleModerator, target: domain.RoleUser},
+		{name: "moderator cannot suspend moderator", actor: domain.RoleModerator, target: domain.RoleModerator, wantError: ErrInsufficientRole},
+		{name: "moderator cannot suspend admin", actor: domain.RoleModerator, target: domain.RoleAdmin, wantError: ErrInsufficientRole},
+		{name: "admin cannot suspend admin", actor: domain.RoleAdmin, target: domain.RoleAdmin, wantError: ErrInsufficientRole},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &domain.User{ID: 2, Role: tt.target}
+			users := &fakeUserRepo{users: []*domain.User{{ID: 1, Role: tt.actor}, target}}
+			connections := &fakeConnections{}
+			audit := &fakeAuditRepo{}
+			svc := NewAdminService(users, nil, audit, connections, nil)
+
+			err := svc.SuspendUser(context.Background(), 1, 2, "spam", "127.0.0.1")
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("SuspendUser() error = %v, want %v", err, tt.wantError)
+			}
+			if suspended := target.IsSuspended(); suspended != (tt.wantError == nil) {
+				t.Errorf("target suspended = %v", suspended)
+			}
+			if tt.wantError != nil && len(connections.disconnected) > 0 {
+				t.Errorf("target disconnected after a rejected suspension")
+			}
+			wantAudited := 0
+			if tt.wantError == nil {
+				wantAudited = 1
+			}
+			if len(audit.entries) != wantAudited {
+				t.Errorf("audit entries = %d, want %d", len(audit.entries), wantAudited)
+			}
+		})
+	}
+}
+
+func TestAdminActionsRequireHigherRole(t *testing.T) {
+	suspended := func() *time.Time {
+		at := time.Now()
+		return &at
+	}
+
+	actions := []struct {
+		name string
+		run  func(svc AdminService, actorID, userID uint) error
+	}{
+		{
+			name: "unsuspend",
+			run: func(svc AdminService, actorID, userID uint) error {
+				return svc.UnsuspendUser(context.Background(), actorID, userID, "127.0.0.1")
+			},
+		},
+		{
+			name: "update role",
+			run: func(svc AdminService, actorID, userID uint) error {
+				return svc.UpdateRole(context.Background(), actorID, userID, domain.RoleModerator, "127.0.0.1")
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		actor     string
+		target    string
+		self      bool
+		wantError error
+	}{
+		{name: "admin acts on user", actor: domain.RoleAdmin, target: domain.RoleUser},
+		{name: "moderator acts on user", actor: domain.RoleModerator, target: domain.RoleUser},
+		{name: "moderator cannot act on admin", actor: domain.RoleModerator, target: domain.RoleAdmin, wantError: ErrInsufficientRole},
+		{name: "admin cannot act on admin", actor: domain.RoleAdmin, target: domain.RoleAdmin, wantError: ErrInsufficientRole},
+		{name: "admin cannot act on themselves", actor: domain.RoleAdmin, self: true, wantError: ErrOwnAccount},
+	}
+
+	for _, action := range actions {
+		for _, tt := range tests {
+			t.Run(action.name+"/"+tt.name, func(t *testing.T) {
+				actor := &domain.User{ID: 1, Role: tt.actor, SuspendedAt: suspended()}
+				target := &domain.User{ID: 2, Role: tt.target, SuspendedAt: suspended()}
+				users := &fakeUserRepo{users: []*domain.User{actor, target}}
+				svc := NewAdminService(users, nil, &fakeAuditRepo{}, &fakeConnections{}, nil)
+
+				targetID := target.ID
+				if tt.self {
+					target, targetID = actor, actor.ID
+				}
+				before := *target
+
+				err := action.run(svc, actor.ID, targetID)
+				if !errors.Is(err, tt.wantError) {
+					t.Fatalf("error = %v, want %v", err, tt.wantError)
+				}
+				changed := target.Role != before.Role || target.IsSuspended() != before.IsSuspended()
+				if changed != (tt.wantError == nil) {
+					t.Errorf("target changed = %v", changed)
+				}
+			})
+		}
+	}
+}
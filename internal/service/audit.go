@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+// recordAudit stores an audit entry. Failures are logged rather than returned
+// so that auditing never blocks the action being audited. The action has
+// already happened, so the entry is stored even if ctx is cancelled.
+func recordAudit(ctx context.Context, auditRepo repository.AuditLogRepository, entry *domain.AuditLog, metadata map[string]interface{}) {
+	if len(metadata) > 0 {
+		if data, err := json.Marshal(metadata); err == nil {
+			entry.Metadata = string(data)
+		}
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if err := auditRepo.Create(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "Failed to record audit event", "action", entry.Action, "error", err)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// botCommandPattern matches "/command", "/command@botname" and
+// "/command args...".
+var botCommandPattern = regexp.MustCompile(`(?s)^/([A-Za-z0-9_]{1,32})(?:@([A-Za-z0-9_.]+))?(?:\s+(.*))?$`)
+
+// parseBotCommand parses a slash command sent to the bot with the given
+// username. Commands addressed to another bot ("/help@otherbot") are
+// ignored. Arguments are split on whitespace; double quotes group words.
+func parseBotCommand(content, botUsername string) (command string, args []string, ok bool) {
+	match := botCommandPattern.FindStringSubmatch(strings.TrimSpace(content))
+	if match == nil {
+		return "", nil, false
+	}
+	if match[2] != "" && !strings.EqualFold(match[2], botUsername) {
+		return "", nil, false
+	}
+
+	return strings.ToLower(match[1]), splitCommandArgs(match[3]), true
+}
+
+func splitCommandArgs(s string) []string {
+	args := []string{}
+	var current strings.Builder
+	inQuotes, hasArg := false, false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+
+	return args
+}
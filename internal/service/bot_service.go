@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"github.com/taufiqoo/go-chat/internal/utils"
+)
+
+const (
+	botTokenPrefix = "bot_"
+	// botTokenTouchInterval limits how often last_used_at is written.
+	botTokenTouchInterval = time.Minute
+)
+
+// botWebhookEvents are sent to a bot's webhook. Like every user-scoped
+// webhook it only receives events involving the bot.
+var botWebhookEvents = domain.StringList{domain.EventMessageCreated, domain.EventBotCommand}
+
+type BotService interface {
+	CreateBot(ctx context.Context, actorID uint, req *domain.CreateBotRequest, clientIP string) (*domain.Bot, error)
+	ListBots(ctx context.Context) ([]domain.Bot, error)
+	GetBot(ctx context.Context, id uint) (*domain.Bot, error)
+	UpdateBot(ctx context.Context, actorID, id uint, req *domain.UpdateBotRequest, clientIP string) (*domain.Bot, error)
+	// CreateToken returns the token record and the plain token, which is not
+	// stored and cannot be shown again.
+	CreateToken(ctx context.Context, actorID, botID uint, req *domain.CreateBotTokenRequest, clientIP string) (*domain.BotToken, string, error)
+	ListTokens(ctx context.Context, botID uint) ([]domain.BotToken, error)
+	RevokeToken(ctx context.Context, actorID, botID, tokenID uint, clientIP string) error
+	// SetWebhook replaces the bot's webhook, generating a new secret.
+	SetWebhook(ctx context.Context, actorID, botID uint, req *domain.SetBotWebhookRequest, clientIP string) (*domain.Webhook, error)
+	RemoveWebhook(ctx context.Context, actorID, botID uint, clientIP string) error
+	Authenticate(ctx context.Context, token string) (*domain.Bot, *domain.BotToken, error)
+}
+
+type botService struct {
+	botRepo     repository.BotRepository
+	tokenRepo   repository.BotTokenRepository
+	userRepo    repository.UserRepository
+	webhookRepo repository.WebhookRepository
+	auditRepo   repository.AuditLogRepository
+}
+
+func NewBotService(
+	botRepo repository.BotRepository,
+	tokenRepo repository.BotTokenRepository,
+	userRepo repository.UserRepository,
+	webhookRepo repository.WebhookRepository,
+	auditRepo repository.AuditLogRepository,
+) BotService {
+	return &botService{
+		botRepo:     botRepo,
+		tokenRepo:   tokenRepo,
+		userRepo:    userRepo,
+		webhookRepo: webhookRepo,
+		auditRepo:   auditRepo,
+	}
+}
+
+func (s *botService) CreateBot(ctx context.Context, actorID uint, req *domain.CreateBotRequest, clientIP string) (*domain.Bot, error) {
+	if existing, _ := s.userRepo.FindByUsername(ctx, req.Username); existing != nil {
+		return nil, errors.New("username already taken")
+	}
+
+	// Bots never log in with a password.
+	randomPassword, err := utils.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	bot := &domain.Bot{
+		CreatedBy:   actorID,
+		Description: req.Description,
+		User: domain.User{
+			Fullname: req.Fullname,
+			Photo:    req.Photo,
+			Username: req.Username,
+			Email:    req.Username + "@bots.invalid",
+			Password: hashedPassword,
+			Role:     domain.RoleUser,
+			IsBot:    true,
+		},
+	}
+	if err := s.botRepo.Create(ctx, bot); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, actorID, domain.AuditActionBotCreated, bot.ID, clientIP, map[string]interface{}{
+		"user_id":  bot.UserID,
+		"username": bot.User.Username,
+	})
+	return bot, nil
+}
+
+func (s *botService) ListBots(ctx context.Context) ([]domain.Bot, error) {
+	return s.botRepo.List(ctx)
+}
+
+func (s *botService) GetBot(ctx context.Context, id uint) (*domain.Bot, error) {
+	bot, err := s.botRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("bot not found")
+	}
+	return bot, nil
+}
+
+func (s *botService) UpdateBot(ctx context.Context, actorID, id uint, req *domain.UpdateBotRequest, clientIP string) (*domain.Bot, error) {
+	bot, err := s.GetBot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Fullname != nil {
+		bot.User.Fullname = *req.Fullname
+	}
+	if req.Photo != nil {
+		bot.User.Photo = *req.Photo
+	}
+	if req.Description != nil {
+		bot.Description = *req.Description
+	}
+
+	if err := s.botRepo.Update(ctx, bot); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, actorID, domain.AuditActionBotUpdated, bot.ID, clientIP, nil)
+	return bot, nil
+}
+
+func (s *botService) CreateToken(ctx context.Context, actorID, botID uint, req *domain.CreateBotTokenRequest, clientIP string) (*domain.BotToken, string, error) {
+	bot, err := s.GetBot(ctx, botID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := utils.RandomString(32)
+	if err != nil {
+		return nil, "", err
+	}
+	plain := botTokenPrefix + secret
+
+	token := &domain.BotToken{
+		BotID:     bot.ID,
+		Name:      req.Name,
+		Prefix:    plain[:len(botTokenPrefix)+8],
+		TokenHash: hashBotToken(plain),
+		Scopes:    uniqueList(req.Scopes),
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, "", err
+	}
+
+	s.audit(ctx, actorID, domain.AuditActionBotTokenCreated, bot.ID, clientIP, map[string]interface{}{
+		"token_id": token.ID,
+		"scopes":   token.Scopes,
+	})
+	return token, plain, nil
+}
+
+func (s *botService) ListTokens(ctx context.Context, botID uint) ([]domain.BotToken, error) {
+	if _, err := s.GetBot(ctx, botID); err != nil {
+		return nil, err
+	}
+	return s.tokenRepo.ListByBot(ctx, botID)
+}
+
+func (s *botService) RevokeToken(ctx context.Context, actorID, botID, tokenID uint, clientIP string) error {
+	token, err := s.tokenRepo.FindByID(ctx, botID, tokenID)
+	if err != nil {
+		return errors.New("token not found")
+	}
+	if token.RevokedAt != nil {
+		return errors.New("token already revoked")
+	}
+
+	if err := s.tokenRepo.Revoke(ctx, token.ID, time.Now()); err != nil {
+		return err
+	}
+
+	s.audit(ctx, actorID, domain.AuditActionBotTokenRevoked, botID, clientIP, map[string]interface{}{
+		"token_id": token.ID,
+	})
+	return nil
+}
+
+func (s *botService) SetWebhook(ctx context.Context, actorID, botID uint, req *domain.SetBotWebhookRequest, clientIP string) (*domain.Webhook, error) {
+	bot, err := s.GetBot(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &domain.Webhook{
+		CreatedBy:   actorID,
+		UserID:      &bot.UserID,
+		URL:         req.URL,
+		Description: "bot " + bot.User.Username,
+		Events:      botWebhookEvents,
+		Secret:      secret,
+		Active:      true,
+	}
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	previous := bot.WebhookID
+	bot.WebhookID = &webhook.ID
+	if err := s.botRepo.Update(ctx, bot); err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		if err := s.webhookRepo.Delete(ctx, *previous); err != nil {
+			return nil, err
+		}
+	}
+
+	s.audit(ctx, actorID, domain.AuditActionBotWebhookSet, bot.ID, clientIP, map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"url":        webhook.URL,
+	})
+	return webhook, nil
+}
+
+func (s *botService) RemoveWebhook(ctx context.Context, actorID, botID uint, clientIP string) error {
+	bot, err := s.GetBot(ctx, botID)
+	if err != nil {
+		return err
+	}
+	if bot.WebhookID == nil {
+		return errors.New("bot has no webhook")
+	}
+
+	webhookID := *bot.WebhookID
+	bot.WebhookID = nil
+	if err := s.botRepo.Update(ctx, bot); err != nil {
+		return err
+	}
+	if err := s.webhookRepo.Delete(ctx, webhookID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, actorID, domain.AuditActionBotWebhookRemoved, bot.ID, clientIP, map[string]interface{}{
+		"webhook_id": webhookID,
+	})
+	return nil
+}
+
+func (s *botService) Authenticate(ctx context.Context, plain string) (*domain.Bot, *domain.BotToken, error) {
+	if !strings.HasPrefix(plain, botTokenPrefix) {
+		return nil, nil, errors.New("invalid bot token")
+	}
+
+	token, err := s.tokenRepo.FindByHash(ctx, hashBotToken(plain))
+	if err != nil || token.RevokedAt != nil {
+		return nil, nil, errors.New("invalid bot token")
+	}
+
+	bot, err := s.botRepo.FindByID(ctx, token.BotID)
+	if err != nil {
+		return nil, nil, errors.New("invalid bot token")
+	}
+	if bot.User.IsSuspended() {
+		return nil, nil, errors.New("bot suspended")
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > botTokenTouchInterval {
+		s.tokenRepo.UpdateLastUsed(ctx, token.ID, now)
+		token.LastUsedAt = &now
+	}
+
+	return bot, token, nil
+}
+
+// hashBotToken uses a plain SHA-256: tokens are long random strings, so a
+// slow password hash would only make every bot request slower.
+func hashBotToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *botService) audit(ctx context.Context, actorID uint, action string, botID uint, clientIP string, metadata map[string]interface{}) {
+	recordAudit(ctx, s.auditRepo, &domain.AuditLog{
+		ActorID:    &actorID,
+		Action:     action,
+		TargetType: "bot",
+		TargetID:   strconv.FormatUint(uint64(botID), 10),
+		IP:         clientIP,
+	}, metadata)
+}
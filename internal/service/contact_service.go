@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+// Notifier pushes a real-time event to every live connection of a user. It
+// is implemented by the WebSocket hub.
+type Notifier interface {
+	NotifyUser(ctx context.Context, userID uint, eventType string, payload interface{})
+}
+
+type ContactService interface {
+	SendRequest(ctx context.Context, requesterID, addresseeID uint) (*domain.ContactRequestResponse, error)
+	AcceptRequest(ctx context.Context, userID, requestID uint) error
+	DeclineRequest(ctx context.Context, userID, requestID uint) error
+	CancelRequest(ctx context.Context, userID, requestID uint) error
+	RemoveContact(ctx context.Context, userID, otherUserID uint) error
+	GetContacts(ctx context.Context, userID uint) ([]domain.ContactResponse, error)
+	GetIncomingRequests(ctx context.Context, userID uint) ([]domain.ContactRequestResponse, error)
+	GetOutgoingRequests(ctx context.Context, userID uint) ([]domain.ContactRequestResponse, error)
+}
+
+type contactService struct {
+	contactRepo    repository.ContactRepository
+	userRepo       repository.UserRepository
+	privacyService PrivacyService
+	notifier       Notifier
+}
+
+func NewContactService(
+	contactRepo repository.ContactRepository,
+	userRepo repository.UserRepository,
+	privacyService PrivacyService,
+	notifier Notifier,
+) ContactService {
+	return &contactService{
+		contactRepo:    contactRepo,
+		userRepo:       userRepo,
+		privacyService: privacyService,
+		notifier:       notifier,
+	}
+}
+
+func (s *contactService) SendRequest(ctx context.Context, requesterID, addresseeID uint) (*domain.ContactRequestResponse, error) {
+	if requesterID == addresseeID {
+		return nil, errors.New("you cannot add yourself as a contact")
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, addresseeID); err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	blocked, err := s.privacyService.IsBlocked(ctx, requesterID, addresseeID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, errors.New("you cannot send a contact request to this user")
+	}
+
+	existing, _ := s.contactRepo.FindBetween(ctx, requesterID, addresseeID)
+	if existing != nil {
+		switch {
+		case existing.Status == domain.ContactStatusAccepted:
+			return nil, errors.New("already in contacts")
+		case existing.RequesterID == requesterID:
+			// A declined request looks the same as a pending one, so the
+			// requester does not learn about the decline.
+			return nil, errors.New("contact request already sent")
+		case existing.Status == domain.ContactStatusPending:
+			// Both users asked each other, treat it as an accept.
+			if err := s.AcceptRequest(ctx, requesterID, existing.ID); err != nil {
+				return nil, err
+			}
+			return s.requestResponse(ctx, existing.ID)
+		}
+
+		// The user who declined earlier is now asking themselves.
+		existing.RequesterID = requesterID
+		existing.AddresseeID = addresseeID
+		existing.Status = domain.ContactStatusPending
+		if err := s.contactRepo.Update(ctx, existing); err != nil {
+			return nil, err
+		}
+		return s.notifyRequest(ctx, existing.ID)
+	}
+
+	contact := &domain.Contact{
+		RequesterID: requesterID,
+		AddresseeID: addresseeID,
+		Status:      domain.ContactStatusPending,
+	}
+	if err := s.contactRepo.Create(ctx, contact); err != nil {
+		return nil, err
+	}
+
+	return s.notifyRequest(ctx, contact.ID)
+}
+
+func (s *contactService) AcceptRequest(ctx context.Context, userID, requestID uint) error {
+	contact, err := s.pendingRequest(ctx, requestID)
+	if err != nil || contact.AddresseeID != userID {
+		return errors.New("contact request not found")
+	}
+
+	contact.Status = domain.ContactStatusAccepted
+	if err := s.contactRepo.Update(ctx, contact); err != nil {
+		return err
+	}
+
+	s.notifier.NotifyUser(ctx, contact.RequesterID, domain.EventContactAccepted, toContactRequestResponse(contact))
+	return nil
+}
+
+func (s *contactService) DeclineRequest(ctx context.Context, userID, requestID uint) error {
+	contact, err := s.pendingRequest(ctx, requestID)
+	if err != nil || contact.AddresseeID != userID {
+		return errors.New("contact request not found")
+	}
+
+	// The requester is deliberately not notified of a decline.
+	contact.Status = domain.ContactStatusDeclined
+	return s.contactRepo.Update(ctx, contact)
+}
+
+func (s *contactService) CancelRequest(ctx context.Context, userID, requestID uint) error {
+	contact, err := s.pendingRequest(ctx, requestID)
+	if err != nil || contact.RequesterID != userID {
+		return errors.New("contact request not found")
+	}
+
+	if err := s.contactRepo.Delete(ctx, contact.ID); err != nil {
+		return err
+	}
+
+	s.notifier.NotifyUser(ctx, contact.AddresseeID, domain.EventContactCancelled, toContactRequestResponse(contact))
+	return nil
+}
+
+func (s *contactService) RemoveContact(ctx context.Context, userID, otherUserID uint) error {
+	ok, err := s.contactRepo.AreContacts(ctx, userID, otherUserID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("contact not found")
+	}
+	return s.contactRepo.DeleteBetween(ctx, userID, otherUserID)
+}
+
+func (s *contactService) GetContacts(ctx context.Context, userID uint) ([]domain.ContactResponse, error) {
+	contacts, err := s.contactRepo.ListAccepted(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]domain.ContactResponse, 0, len(contacts))
+	for i := range contacts {
+		other := contacts[i].OtherUser(userID)
+
+		online := false
+		if presence, err := s.privacyService.GetPresence(ctx, userID, other.ID); err == nil {
+			online = presence.Online
+		}
+
+		responses = append(responses, domain.ContactResponse{
+			UserID:   other.ID,
+			Fullname: other.Fullname,
+			Photo:    other.Photo,
+			Username: other.Username,
+			Online:   online,
+			Since:    contacts[i].UpdatedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+func (s *contactService) GetIncomingRequests(ctx context.Context, userID uint) ([]domain.ContactRequestResponse, error) {
+	contacts, err := s.contactRepo.ListIncoming(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toContactRequestResponses(contacts), nil
+}
+
+func (s *contactService) GetOutgoingRequests(ctx context.Context, userID uint) ([]domain.ContactRequestResponse, error) {
+	contacts, err := s.contactRepo.ListOutgoing(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toContactRequestResponses(contacts), nil
+}
+
+func (s *contactService) pendingRequest(ctx context.Context, requestID uint) (*domain.Contact, error) {
+	contact, err := s.contactRepo.FindByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if contact.Status != domain.ContactStatusPending {
+		return nil, errors.New("contact request is no longer pending")
+	}
+	return contact, nil
+}
+
+func (s *contactService) notifyRequest(ctx context.Context, requestID uint) (*domain.ContactRequestResponse, error) {
+	response, err := s.requestResponse(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifier.NotifyUser(ctx, response.To.ID, domain.EventContactRequest, response)
+	return response, nil
+}
+
+func (s *contactService) requestResponse(ctx context.Context, requestID uint) (*domain.ContactRequestResponse, error) {
+	contact, err := s.contactRepo.FindByID(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	response := toContactRequestResponse(contact)
+	return &response, nil
+}
+
+func toContactRequestResponse(contact *domain.Contact) domain.ContactRequestResponse {
+	return domain.ContactRequestResponse{
+		ID:     contact.ID,
+		Status: contact.Status,
+		From: domain.UserInfo{
+			ID:       contact.Requester.ID,
+			Username: contact.Requester.Username,
+			IsBot:    contact.Requester.IsBot,
+		},
+		To: domain.UserInfo{
+			ID:       contact.Addressee.ID,
+			Username: contact.Addressee.Username,
+			IsBot:    contact.Addressee.IsBot,
+		},
+		CreatedAt: contact.CreatedAt,
+	}
+}
+
+func toContactRequestResponses(contacts []domain.Contact) []domain.ContactRequestResponse {
+	responses := make([]domain.ContactRequestResponse, 0, len(contacts))
+	for i := range contacts {
+		responses = append(responses, toContactRequestResponse(&contacts[i]))
+	}
+	return responses
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+type fakeContactRepo struct {
+	repository.ContactRepository
+	contact *domain.Contact
+}
+
+func (r *fakeContactRepo) FindBetween(ctx context.Context, userID, otherUserID uint) (*domain.Contact, error) {
+	return r.contact, nil
+}
+
+type fakePrivacyService struct {
+	PrivacyService
+}
+
+func (p *fakePrivacyService) IsBlocked(ctx context.Context, userID, otherUserID uint) (bool, error) {
+	return false, nil
+}
+
+func TestSendRequestDoesNotRevealDecline(t *testing.T) {
+	const requester, addressee = 1, 2
+
+	var messages []string
+	for _, status := range []string{domain.ContactStatusPending, domain.ContactStatusDeclined} {
+		contacts := &fakeContactRepo{contact: &domain.Contact{
+			ID:          10,
+			RequesterID: requester,
+			AddresseeID: addressee,
+			Status:      status,
+		}}
+		users := &fakeUserRepo{users: []*domain.User{{ID: requester}, {ID: addressee}}}
+		svc := NewContactService(contacts, users, &fakePrivacyService{}, nil)
+
+		_, err := svc.SendRequest(context.Background(), requester, addressee)
+		if err == nil {
+			t.Fatalf("SendRequest() with a %s request succeeded", status)
+		}
+		messages = append(messages, err.Error())
+	}
+
+	if messages[0] != messages[1] {
+		t.Errorf("pending request error %q, declined request error %q; want the same", messages[0], messages[1])
+	}
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"github.com/taufiqoo/go-chat/internal/utils"
+	"github.com/taufiqoo/go-chat/pkg/mailer"
+)
+
+const (
+	digestBatchSize        = 100
+	digestMaxConversations = 10
+	digestPreviewLength    = 100
+	// Unsubscribe links must keep working in old emails.
+	unsubscribeTokenTTL = 365 * 24 * time.Hour
+)
+
+//go:embed templates/digest.html templates/digest.txt
+var digestTemplates embed.FS
+
+var (
+	digestHTML = htmltemplate.Must(htmltemplate.ParseFS(digestTemplates, "templates/digest.html"))
+	digestText = texttemplate.Must(texttemplate.ParseFS(digestTemplates, "templates/digest.txt"))
+)
+
+type DigestService interface {
+	GetPreferences(ctx context.Context, userID uint) (*domain.DigestPreferences, error)
+	UpdatePreferences(ctx context.Context, userID uint, req *domain.UpdateDigestPreferencesRequest) (*domain.DigestPreferences, error)
+	Unsubscribe(ctx context.Context, token string) error
+	Run(ctx context.Context)
+}
+
+type digestService struct {
+	digestRepo    repository.DigestRepository
+	messageRepo   repository.MessageRepository
+	presence      PresenceChecker
+	mailer        mailer.Mailer
+	cfg           *config.Config
+	idle          time.Duration
+	checkInterval time.Duration
+}
+
+func NewDigestService(
+	digestRepo repository.DigestRepository,
+	messageRepo repository.MessageRepository,
+	presence PresenceChecker,
+	mail mailer.Mailer,
+	cfg *config.Config,
+) DigestService {
+	s := &digestService{
+		digestRepo:    digestRepo,
+		messageRepo:   messageRepo,
+		presence:      presence,
+		mailer:        mail,
+		cfg:           cfg,
+		idle:          time.Duration(cfg.DigestIdleHours) * time.Hour,
+		checkInterval: time.Duration(cfg.DigestCheckInterval) * time.Minute,
+	}
+
+	if s.idle <= 0 {
+		s.idle = 24 * time.Hour
+	}
+	if s.checkInterval <= 0 {
+		s.checkInterval = 15 * time.Minute
+	}
+
+	return s
+}
+
+type digestData struct {
+	Name           string
+	TotalUnread    int64
+	Conversations  []digestConversation
+	More           int
+	Frequency      string
+	AppURL         string
+	UnsubscribeURL string
+}
+
+type digestConversation struct {
+	Name    string
+	Unread  int
+	Preview string
+}
+
+func (s *digestService) GetPreferences(ctx context.Context, userID uint) (*domain.DigestPreferences, error) {
+	return s.digestRepo.FindByUserID(ctx, userID)
+}
+
+func (s *digestService) UpdatePreferences(ctx context.Context, userID uint, req *domain.UpdateDigestPreferencesRequest) (*domain.DigestPreferences, error) {
+	preferences, err := s.digestRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Enabled != nil {
+		preferences.Enabled = *req.Enabled
+	}
+	if req.Frequency != "" {
+		preferences.Frequency = req.Frequency
+	}
+
+	if err := s.digestRepo.Save(ctx, preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (s *digestService) Unsubscribe(ctx context.Context, token string) error {
+	userID, err := utils.ValidateActionToken(token, utils.TokenPurposeUnsubscribe, s.cfg.JWTSecret)
+	if err != nil {
+		return errors.New("invalid unsubscribe link")
+	}
+
+	preferences, err := s.digestRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	preferences.Enabled = false
+	return s.digestRepo.Save(ctx, preferences)
+}
+
+func (s *digestService) Run(ctx context.Context) {
+	if !s.cfg.DigestEnabled {
+		return
+	}
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sendDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *digestService) sendDue(ctx context.Context) {
+	now := time.Now()
+	var afterID uint
+
+	for ctx.Err() == nil {
+		users, err := s.digestRepo.FindDue(ctx, afterID, now.Add(-s.idle), now.Add(-24*time.Hour), now.Add(-7*24*time.Hour), digestBatchSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to find users due for a digest", "error", err)
+			return
+		}
+
+		for i := range users {
+			s.sendDigest(ctx, &users[i], now)
+		}
+
+		if len(users) < digestBatchSize {
+			return
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
+
+func (s *digestService) sendDigest(ctx context.Context, user *domain.User, now time.Time) {
+	if s.presence.IsOnline(user.ID) {
+		return
+	}
+
+	preferences, err := s.digestRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load digest preferences", "user_id", user.ID, "error", err)
+		return
+	}
+
+	chats, err := s.messageRepo.GetChatList(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load conversations for digest", "user_id", user.ID, "error", err)
+		return
+	}
+	total, err := s.messageRepo.GetUnreadCount(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to count unread messages for digest", "user_id", user.ID, "error", err)
+		return
+	}
+
+	data := digestData{
+		Name:        user.Fullname,
+		TotalUnread: total,
+		Frequency:   preferences.Frequency,
+		AppURL:      s.cfg.AppBaseURL,
+	}
+	for _, chat := range chats {
+		if chat.UnreadCount == 0 {
+			continue
+		}
+		if len(data.Conversations) == digestMaxConversations {
+			data.More++
+			continue
+		}
+
+		conversation := digestConversation{Name: chat.Fullname, Unread: chat.UnreadCount}
+		if conversation.Name == "" {
+			conversation.Name = chat.Username
+		}
+		if !chat.IsSender {
+			conversation.Preview = truncate(chat.Content, digestPreviewLength)
+		}
+		data.Conversations = append(data.Conversations, conversation)
+	}
+	if total == 0 || len(data.Conversations) == 0 {
+		return
+	}
+
+	token, err := utils.GenerateActionToken(utils.TokenPurposeUnsubscribe, user.ID, s.cfg.JWTSecret, unsubscribeTokenTTL)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to generate unsubscribe token", "user_id", user.ID, "error", err)
+		return
+	}
+	data.UnsubscribeURL = fmt.Sprintf("%s/api/v1/digest/unsubscribe?token=%s", strings.TrimSuffix(s.cfg.AppBaseURL, "/"), token)
+
+	var html, text bytes.Buffer
+	if err := digestHTML.Execute(&html, data); err != nil {
+		slog.ErrorContext(ctx, "Failed to render digest", "user_id", user.ID, "error", err)
+		return
+	}
+	if err := digestText.Execute(&text, data); err != nil {
+		slog.ErrorContext(ctx, "Failed to render digest", "user_id", user.ID, "error", err)
+		return
+	}
+
+	// Claim before sending so two workers never email the same digest; a
+	// failed send waits for the next interval.
+	claimed, err := s.digestRepo.ClaimSend(ctx, user.ID, now.Add(-preferences.Interval()), now)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record digest", "user_id", user.ID, "error", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	subject := fmt.Sprintf("You have %d unread messages", total)
+	if total == 1 {
+		subject = "You have 1 unread message"
+	}
+
+	err = s.mailer.Send(&mailer.Mail{
+		To:       user.Email,
+		Subject:  subject,
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + data.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send digest", "user_id", user.ID, "error", err)
+	}
+}
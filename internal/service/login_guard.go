@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/utils"
+	"github.com/taufiqoo/go-chat/pkg/mailer"
+)
+
+const unlockTokenTTL = 24 * time.Hour
+
+// LoginThrottledError is returned while an email address or IP address is
+// temporarily blocked. The message is the same whether or not the account
+// exists, so it cannot be used to enumerate users.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return "too many login attempts, please try again later"
+}
+
+func emailAttemptKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (u *userService) checkLoginAllowed(ctx context.Context, email, clientIP string) error {
+	var retryAfter time.Duration
+	for _, key := range []string{emailAttemptKey(email), ipAttemptKey(clientIP)} {
+		blocked, err := u.loginAttempts.BlockedFor(ctx, key)
+		if err != nil {
+			return err
+		}
+		if blocked > retryAfter {
+			retryAfter = blocked
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure applies exponential backoff once an email address has
+// LoginBackoffAfter failures and locks it out after LoginMaxAttempts. The IP
+// address has its own, higher, lockout threshold to slow down credential
+// stuffing across many accounts.
+func (u *userService) recordLoginFailure(ctx context.Context, email, clientIP string, user *domain.User) {
+	// Hanging up after a wrong password must not skip the count.
+	ctx = context.WithoutCancel(ctx)
+	window := time.Duration(u.cfg.LoginAttemptWindow) * time.Minute
+	lockout := time.Duration(u.cfg.LoginLockoutDuration) * time.Minute
+
+	emailKey := emailAttemptKey(email)
+	count, err := u.loginAttempts.IncrementFailures(ctx, emailKey, window)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record login failure", "error", err)
+		return
+	}
+
+	switch {
+	case count >= int64(u.cfg.LoginMaxAttempts):
+		u.lockOut(ctx, emailKey, lockout)
+		u.onAccountLocked(ctx, email, clientIP, user, count)
+	case count >= int64(u.cfg.LoginBackoffAfter):
+		u.block(ctx, emailKey, u.backoffDelay(count))
+	}
+
+	ipKey := ipAttemptKey(clientIP)
+	ipCount, err := u.loginAttempts.IncrementFailures(ctx, ipKey, window)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record login failure", "error", err)
+		return
+	}
+
+	if ipCount >= int64(u.cfg.LoginIPMaxAttempts) {
+		u.lockOut(ctx, ipKey, lockout)
+		recordAudit(ctx, u.auditRepo, &domain.AuditLog{
+			Action:     domain.AuditActionIPLocked,
+			TargetType: "ip",
+			TargetID:   clientIP,
+			IP:         clientIP,
+		}, map[string]interface{}{"failures": ipCount, "duration": lockout.String()})
+	}
+}
+
+// lockOut blocks the key for the lockout duration and starts counting its
+// failures again afterwards.
+func (u *userService) lockOut(ctx context.Context, key string, lockout time.Duration) {
+	u.block(ctx, key, lockout)
+	if err := u.loginAttempts.ResetFailures(ctx, key); err != nil {
+		slog.ErrorContext(ctx, "Failed to reset login failures", "error", err)
+	}
+}
+
+func (u *userService) block(ctx context.Context, key string, duration time.Duration) {
+	if err := u.loginAttempts.Block(ctx, key, duration); err != nil {
+		slog.ErrorContext(ctx, "Failed to block login attempts", "duration", duration.String(), "error", err)
+	}
+}
+
+func (u *userService) recordLoginSuccess(ctx context.Context, email string) {
+	if err := u.loginAttempts.ResetFailures(ctx, emailAttemptKey(email)); err != nil {
+		slog.ErrorContext(ctx, "Failed to reset login failures", "error", err)
+	}
+}
+
+func (u *userService) backoffDelay(count int64) time.Duration {
+	exponent := count - int64(u.cfg.LoginBackoffAfter)
+	if exponent > 16 {
+		exponent = 16
+	}
+
+	delay := time.Duration(u.cfg.LoginBackoffBaseSeconds) * time.Second << exponent
+	if max := time.Duration(u.cfg.LoginBackoffMaxSeconds) * time.Second; delay > max {
+		delay = max
+	}
+	return delay
+}
+
+func (u *userService) onAccountLocked(ctx context.Context, email, clientIP string, user *domain.User, failures int64) {
+	entry := &domain.AuditLog{
+		Action:     domain.AuditActionAccountLocked,
+		TargetType: "email",
+		TargetID:   strings.ToLower(email),
+		IP:         clientIP,
+	}
+	if user != nil {
+		entry.TargetType = "user"
+		entry.TargetID = strconv.FormatUint(uint64(user.ID), 10)
+	}
+	recordAudit(ctx, u.auditRepo, entry, map[string]interface{}{
+		"failures": failures,
+		"duration": fmt.Sprintf("%dm", u.cfg.LoginLockoutDuration),
+	})
+
+	if user != nil {
+		// Send asynchronously so the response time does not reveal whether
+		// the account exists.
+		go u.sendUnlockEmail(user)
+	}
+}
+
+func (u *userService) sendUnlockEmail(user *domain.User) {
+	token, err := utils.GenerateActionToken(utils.TokenPurposeUnlock, user.ID, u.cfg.JWTSecret, unlockTokenTTL)
+	if err != nil {
+		slog.Error("Failed to generate unlock token", "user_id", user.ID, "error", err)
+		return
+	}
+
+	link := fmt.Sprintf("%s/api/v1/auth/unlock?token=%s", strings.TrimSuffix(u.cfg.AppBaseURL, "/"), token)
+	err = u.mailer.Send(&mailer.Mail{
+		To:      user.Email,
+		Subject: "Your account has been temporarily locked",
+		TextBody: fmt.Sprintf("Hi %s,\n\nWe locked your account for %d minutes after several failed login attempts.\n"+
+			"If this was you, you can unlock it now:\n\n%s\n\nIf it was not you, consider changing your password.\n",
+			user.Fullname, u.cfg.LoginLockoutDuration, link),
+	})
+	if err != nil {
+		slog.Error("Failed to send unlock email", "user_id", user.ID, "error", err)
+	}
+}
+
+func (u *userService) unlock(ctx context.Context, user *domain.User, actorID uint, clientIP string) error {
+	if err := u.loginAttempts.Unblock(ctx, emailAttemptKey(user.Email)); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditRepo, &domain.AuditLog{
+		ActorID:    &actorID,
+		Action:     domain.AuditActionAccountUnlocked,
+		TargetType: "user",
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+		IP:         clientIP,
+	}, nil)
+
+	return nil
+}
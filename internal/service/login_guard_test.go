@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+// fakeLoginAttempts counts failures without a window and records blocks.
+type fakeLoginAttempts struct {
+	repository.LoginAttemptRepository
+	failures map[string]int64
+	blocks   map[string]time.Duration
+}
+
+func newFakeLoginAttempts() *fakeLoginAttempts {
+	return &fakeLoginAttempts{failures: make(map[string]int64), blocks: make(map[string]time.Duration)}
+}
+
+func (f *fakeLoginAttempts) IncrementFailures(ctx context.Context, key string, window time.Duration) (int64, error) {
+	f.failures[key]++
+	return f.failures[key], nil
+}
+
+func (f *fakeLoginAttempts) ResetFailures(ctx context.Context, key string) error {
+	delete(f.failures, key)
+	return nil
+}
+
+func (f *fakeLoginAttempts) Block(ctx context.Context, key string, duration time.Duration) error {
+	f.blocks[key] = duration
+	return nil
+}
+
+func loginGuardConfig() *config.Config {
+	return &config.Config{
+		LoginMaxAttempts:        5,
+		LoginAttemptWindow:      15,
+		LoginLockoutDuration:    30,
+		LoginBackoffAfter:       3,
+		LoginBackoffBaseSeconds: 2,
+		LoginBackoffMaxSeconds:  60,
+		LoginIPMaxAttempts:      8,
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		count int64
+		want  time.Duration
+	}{
+		{count: 3, want: 2 * time.Second},
+		{count: 4, want: 4 * time.Second},
+		{count: 5, want: 8 * time.Second},
+		{count: 8, want: 60 * time.Second},
+		// The exponent is capped so the shift cannot overflow.
+		{count: 1000, want: 60 * time.Second},
+	}
+
+	svc := &userService{cfg: loginGuardConfig()}
+	for _, tt := range tests {
+		if got := svc.backoffDelay(tt.count); got != tt.want {
+			t.Errorf("backoffDelay(%d) = %v, want %v", tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestRecordLoginFailure(t *testing.T) {
+	const email, ip = "user@example.com", "10.0.0.1"
+	emailKey, ipKey := emailAttemptKey(email), ipAttemptKey(ip)
+
+	tests := []struct {
+		name string
+		// failures are recorded before the one under test.
+		failures      int64
+		ipFailures    int64
+		wantBlock     time.Duration
+		wantIPBlock   time.Duration
+		wantFailures  int64
+		wantAuditKind string
+	}{
+		{name: "below the backoff threshold", failures: 0, wantFailures: 1},
+		{name: "first backoff", failures: 2, wantBlock: 2 * time.Second, wantFailures: 3},
+		{name: "growing backoff", failures: 3, wantBlock: 4 * time.Second, wantFailures: 4},
+		{
+			name:          "account lockout",
+			failures:      4,
+			wantBlock:     30 * time.Minute,
+			wantAuditKind: domain.AuditActionAccountLocked,
+		},
+		{
+			name:          "IP lockout",
+			ipFailures:    7,
+			wantIPBlock:   30 * time.Minute,
+			wantFailures:  1,
+			wantAuditKind: domain.AuditActionIPLocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := newFakeLoginAttempts()
+			attempts.failures[emailKey] = tt.failures
+			attempts.failures[ipKey] = tt.ipFailures
+			audit := &fakeAuditRepo{}
+			svc := &userService{loginAttempts: attempts, auditRepo: audit, cfg: loginGuardConfig()}
+
+			svc.recordLoginFailure(context.Background(), email, ip, nil)
+
+			if got := attempts.blocks[emailKey]; got != tt.wantBlock {
+				t.Errorf("email block = %v, want %v", got, tt.wantBlock)
+			}
+			if got := attempts.blocks[ipKey]; got != tt.wantIPBlock {
+				t.Errorf("IP block = %v, want %v", got, tt.wantIPBlock)
+			}
+			// A lockout starts counting again.
+			if got := attempts.failures[emailKey]; got != tt.wantFailures {
+				t.Errorf("email failures = %d, want %d", got, tt.wantFailures)
+			}
+
+			var kinds []string
+			for _, entry := range audit.entries {
+				kinds = append(kinds, entry.Action)
+			}
+			wantKinds := 0
+			if tt.wantAuditKind != "" {
+				wantKinds = 1
+			}
+			if len(kinds) != wantKinds || (wantKinds == 1 && kinds[0] != tt.wantAuditKind) {
+				t.Errorf("audit entries = %v, want %q", kinds, tt.wantAuditKind)
+			}
+		})
+	}
+}
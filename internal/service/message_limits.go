@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/pkg/metrics"
+	"github.com/taufiqoo/go-chat/pkg/ratelimit"
+)
+
+// RateLimitedError is returned when a user sends messages faster than the
+// configured limits allow.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return "you are sending messages too fast, please slow down"
+}
+
+// MessageRateLimits holds the token bucket rules for sending messages.
+// PerUser applies to every message a user sends. NewConversation applies
+// per receiver while the two users are not contacts and the receiver has
+// never replied, which is where unsolicited spam comes from.
+type MessageRateLimits struct {
+	PerUser         ratelimit.Rule
+	NewConversation ratelimit.Rule
+}
+
+func MessageRateLimitsFromConfig(cfg *config.Config) MessageRateLimits {
+	return MessageRateLimits{
+		PerUser: ratelimit.Rule{
+			Rate:  cfg.MessageRateLimit,
+			Per:   time.Duration(cfg.MessageRateWindow) * time.Second,
+			Burst: cfg.MessageRateBurst,
+		},
+		NewConversation: ratelimit.Rule{
+			Rate:  cfg.MessageNewConversationRateLimit,
+			Per:   time.Duration(cfg.MessageNewConversationWindow) * time.Second,
+			Burst: cfg.MessageNewConversationBurst,
+		},
+	}
+}
+
+func (c *messageService) checkUserRate(ctx context.Context, senderID uint) error {
+	return c.takeToken(ctx, "messages_user", fmt.Sprintf("messages:user:%d", senderID), c.limits.PerUser)
+}
+
+func (c *messageService) checkConversationRate(ctx context.Context, senderID, receiverID uint) error {
+	if !c.limits.NewConversation.Enabled() {
+		return nil
+	}
+
+	if ok, err := c.contactRepo.AreContacts(ctx, senderID, receiverID); err != nil || ok {
+		return err
+	}
+	if replied, err := c.messageRepo.HasSent(ctx, receiverID, senderID); err != nil || replied {
+		return err
+	}
+
+	return c.takeToken(ctx, "messages_new_conversation", fmt.Sprintf("messages:conversation:%d:%d", senderID, receiverID), c.limits.NewConversation)
+}
+
+func (c *messageService) takeToken(ctx context.Context, policy, key string, rule ratelimit.Rule) error {
+	result, err := c.limiter.Allow(ctx, key, rule)
+	if err != nil {
+		// Never block sending because the limiter is unavailable.
+		slog.ErrorContext(ctx, "Rate limiter error", "policy", policy, "key", key, "error", err)
+		return nil
+	}
+	if !result.Allowed {
+		metrics.RateLimited(policy)
+		return &RateLimitedError{RetryAfter: result.RetryAfter}
+	}
+	return nil
+}
@@ -1,66 +1,300 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log/slog"
+	"time"
 
 	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/moderation"
 	"github.com/taufiqoo/go-chat/internal/repository"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+	"github.com/taufiqoo/go-chat/pkg/ratelimit"
+	"github.com/taufiqoo/go-chat/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
 )
 
+var (
+	// ErrAccountSuspended is returned when a suspended user tries to send a
+	// message with a session opened before the suspension.
+	ErrAccountSuspended = errors.New("account suspended")
+	// ErrMessageNotFound is also returned for messages the user did not
+	// receive, so their IDs cannot be probed.
+	ErrMessageNotFound = errors.New("message not found")
+	// ErrReceiverNotFound is returned when sending to a user that does not
+	// exist.
+	ErrReceiverNotFound = errors.New("receiver not found")
+)
+
+// MessageRejectedError is returned when a moderation filter rejects the
+// content of a message.
+type MessageRejectedError struct {
+	Reason string
+}
+
+func (e *MessageRejectedError) Error() string {
+	return "message rejected: " + e.Reason
+}
+
 type MessageService interface {
-	SendMessage(senderID uint, req *domain.SendMessageRequest) (*domain.Message, error)
-	GetChatHistory(userID, otherUserID uint, limit int) ([]domain.Message, error)
-	MarkMessageAsRead(messageID uint) error
-	GetUnreadCount(userID uint) (int64, error)
-	GetChatList(userID uint) ([]domain.ChatListItem, error)
+	SendMessage(ctx context.Context, senderID uint, req *domain.SendMessageRequest) (*domain.Message, error)
+	GetChatHistory(ctx context.Context, userID, otherUserID uint, limit int) ([]domain.Message, error)
+	MarkMessageAsRead(ctx context.Context, userID, messageID uint) error
+	GetUnreadCount(ctx context.Context, userID uint) (int64, error)
+	GetChatList(ctx context.Context, userID uint) ([]domain.ChatListItem, error)
 }
 
 type messageService struct {
-	messageRepo repository.MessageRepository
-	userRepo    repository.UserRepository
+	messageRepo    repository.MessageRepository
+	userRepo       repository.UserRepository
+	contactRepo    repository.ContactRepository
+	flagRepo       repository.ModerationFlagRepository
+	privacyService PrivacyService
+	moderator      moderation.Moderator
+	limiter        ratelimit.Limiter
+	limits         MessageRateLimits
+	outbox         OutboxRelay
 }
 
-func NewMessageService(messageRepo repository.MessageRepository, userRepo repository.UserRepository) MessageService {
+func NewMessageService(
+	messageRepo repository.MessageRepository,
+	userRepo repository.UserRepository,
+	contactRepo repository.ContactRepository,
+	flagRepo repository.ModerationFlagRepository,
+	privacyService PrivacyService,
+	moderator moderation.Moderator,
+	limiter ratelimit.Limiter,
+	limits MessageRateLimits,
+	outbox OutboxRelay,
+) MessageService {
 	return &messageService{
-		messageRepo: messageRepo,
-		userRepo:    userRepo,
+		messageRepo:    messageRepo,
+		userRepo:       userRepo,
+		contactRepo:    contactRepo,
+		flagRepo:       flagRepo,
+		privacyService: privacyService,
+		moderator:      moderator,
+		limiter:        limiter,
+		limits:         limits,
+		outbox:         outbox,
 	}
 }
 
-func (c *messageService) SendMessage(senderID uint, req *domain.SendMessageRequest) (*domain.Message, error) {
-	_, err := c.userRepo.FindByID(req.ReceiverID)
+func (c *messageService) SendMessage(ctx context.Context, senderID uint, req *domain.SendMessageRequest) (message *domain.Message, err error) {
+	ctx, span := tracing.Start(ctx, "MessageService.SendMessage")
+	span.SetAttributes(
+		attribute.Int64("chat.sender_id", int64(senderID)),
+		attribute.Int64("chat.receiver_id", int64(req.ReceiverID)),
+	)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	sender, err := c.userRepo.FindByID(ctx, senderID)
 	if err != nil {
-		return nil, errors.New("receiver not found")
+		return nil, err
+	}
+	if sender.IsSuspended() {
+		return nil, ErrAccountSuspended
+	}
+
+	if err := c.checkUserRate(ctx, senderID); err != nil {
+		return nil, err
+	}
+
+	receiver, err := c.userRepo.FindByID(ctx, req.ReceiverID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReceiverNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	message := &domain.Message{
+	if err := c.privacyService.CanMessage(ctx, senderID, req.ReceiverID); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkConversationRate(ctx, senderID, req.ReceiverID); err != nil {
+		return nil, err
+	}
+
+	verdict := c.moderator.Moderate(&moderation.Input{
 		SenderID:   senderID,
 		ReceiverID: req.ReceiverID,
 		Content:    req.Content,
+	})
+	if verdict.Action == moderation.ActionReject {
+		return nil, &MessageRejectedError{Reason: verdict.RejectReason()}
 	}
 
-	if err := c.messageRepo.Create(message); err != nil {
+	message = &domain.Message{
+		SenderID:   senderID,
+		ReceiverID: req.ReceiverID,
+		Content:    verdict.Content,
+	}
+
+	// The events are stored with the message so they are not lost if the
+	// process dies before publishing them.
+	var events []eventbus.Event
+	stored, err := c.messageRepo.CreateWithEvents(ctx, message, func(message *domain.Message) ([]domain.OutboxEvent, error) {
+		events = messageEvents(message, sender, receiver)
+		return c.outbox.Prepare(events)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return c.messageRepo.FindByID(message.ID)
+	// The message is committed, so the rest is done even if the client has
+	// gone away in the meantime.
+	ctx = context.WithoutCancel(ctx)
+
+	for _, flagged := range verdict.Flagged() {
+		err := c.flagRepo.Create(ctx, &domain.ModerationFlag{
+			MessageID:  message.ID,
+			SenderID:   senderID,
+			ReceiverID: req.ReceiverID,
+			Content:    req.Content,
+			Filter:     flagged.Filter,
+			Reason:     flagged.Reason,
+			Status:     domain.FlagStatusPending,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to record moderation flag", "message_id", message.ID, "error", err)
+		}
+	}
+
+	// The message is stored, so a failed reload must not make the client
+	// send it again.
+	saved, err := c.messageRepo.FindByID(ctx, message.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load message", "message_id", message.ID, "error", err)
+		saved = message
+	}
+
+	c.outbox.Publish(ctx, events, stored)
+
+	return saved, nil
 }
 
-func (c *messageService) GetChatHistory(userID, otherUserID uint, limit int) ([]domain.Message, error) {
+// messageEvents returns the events caused by a new message: message.created,
+// and bot.command if it is a slash command sent to a bot.
+func messageEvents(message *domain.Message, sender, receiver *domain.User) []eventbus.Event {
+	// Live connections get the message with its sender and receiver, as it
+	// is loaded from the database.
+	full := *message
+	full.Sender = *sender
+	full.Receiver = *receiver
+
+	events := []eventbus.Event{
+		eventbus.NewEvent(domain.EventMessageCreated, domain.MessageCreatedEvent{
+			ID:         message.ID,
+			SenderID:   message.SenderID,
+			ReceiverID: message.ReceiverID,
+			Content:    message.Content,
+			CreatedAt:  message.CreatedAt,
+			Message:    &full,
+		}),
+	}
+
+	if receiver.IsBot {
+		if command, args, ok := parseBotCommand(message.Content, receiver.Username); ok {
+			events = append(events, eventbus.NewEvent(domain.EventBotCommand, domain.BotCommandEvent{
+				MessageID: message.ID,
+				BotUserID: receiver.ID,
+				SenderID:  message.SenderID,
+				Command:   command,
+				Args:      args,
+				Text:      message.Content,
+				CreatedAt: message.CreatedAt,
+			}))
+		}
+	}
+
+	return events
+}
+
+func (c *messageService) GetChatHistory(ctx context.Context, userID, otherUserID uint, limit int) ([]domain.Message, error) {
 	if limit <= 0 {
 		limit = 50
 	}
-	return c.messageRepo.GetChatHistory(userID, otherUserID, limit)
+	messages, err := c.messageRepo.GetChatHistory(ctx, userID, otherUserID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hide read state of our own messages if the other user does not share
+	// read receipts with us.
+	if !c.privacyService.CanSeeReadReceipts(ctx, userID, otherUserID) {
+		for i := range messages {
+			if messages[i].SenderID == userID {
+				messages[i].IsRead = false
+			}
+		}
+	}
+
+	return messages, nil
 }
 
-func (c *messageService) MarkMessageAsRead(messageID uint) error {
-	return c.messageRepo.MarkAsRead(messageID)
+// MarkMessageAsRead marks a message the user received as read.
+func (c *messageService) MarkMessageAsRead(ctx context.Context, userID, messageID uint) error {
+	message, err := c.messageRepo.FindByID(ctx, messageID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMessageNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if message.ReceiverID != userID {
+		return ErrMessageNotFound
+	}
+	if message.IsRead {
+		return nil
+	}
+
+	events := []eventbus.Event{
+		eventbus.NewEvent(domain.EventMessageRead, domain.MessageReadEvent{
+			ID:         message.ID,
+			SenderID:   message.SenderID,
+			ReceiverID: message.ReceiverID,
+			ReadAt:     time.Now(),
+		}),
+	}
+	rows, err := c.outbox.Prepare(events)
+	if err != nil {
+		return err
+	}
+
+	stored, err := c.messageRepo.MarkAsReadWithEvents(ctx, messageID, rows)
+	if err != nil {
+		return err
+	}
+	// Another request marked it read first and published the event.
+	if len(stored) == 0 {
+		return nil
+	}
+
+	c.outbox.Publish(context.WithoutCancel(ctx), events, stored)
+	return nil
 }
 
-func (c *messageService) GetUnreadCount(userID uint) (int64, error) {
-	return c.messageRepo.GetUnreadCount(userID)
+func (c *messageService) GetUnreadCount(ctx context.Context, userID uint) (int64, error) {
+	return c.messageRepo.GetUnreadCount(ctx, userID)
 }
 
-func (s *messageService) GetChatList(userID uint) ([]domain.ChatListItem, error) {
-	return s.messageRepo.GetChatList(userID)
+func (s *messageService) GetChatList(ctx context.Context, userID uint) ([]domain.ChatListItem, error) {
+	chatList, err := s.messageRepo.GetChatList(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range chatList {
+		if chatList[i].IsSender && !s.privacyService.CanSeeReadReceipts(ctx, userID, chatList[i].UserID) {
+			chatList[i].IsRead = false
+		}
+	}
+
+	return chatList, nil
 }
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type fakeMessageRepo struct {
+	repository.MessageRepository
+	messages map[uint]*domain.Message
+}
+
+func (r *fakeMessageRepo) FindByID(ctx context.Context, id uint) (*domain.Message, error) {
+	if message, ok := r.messages[id]; ok {
+		return message, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeMessageRepo) MarkAsReadWithEvents(ctx context.Context, id uint, events []domain.OutboxEvent) ([]domain.OutboxEvent, error) {
+	if r.messages[id].IsRead {
+		return nil, nil
+	}
+	r.messages[id].IsRead = true
+	return events, nil
+}
+
+func TestMarkMessageAsRead(t *testing.T) {
+	const sender, receiver, stranger = 1, 2, 3
+
+	tests := []struct {
+		name      string
+		userID    uint
+		messageID uint
+		wantErr   error
+	}{
+		{name: "receiver", userID: receiver, messageID: 10},
+		{name: "sender cannot mark their own message", userID: sender, messageID: 10, wantErr: ErrMessageNotFound},
+		{name: "stranger", userID: stranger, messageID: 10, wantErr: ErrMessageNotFound},
+		{name: "unknown message", userID: receiver, messageID: 11, wantErr: ErrMessageNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeMessageRepo{messages: map[uint]*domain.Message{
+				10: {ID: 10, SenderID: sender, ReceiverID: receiver},
+			}}
+			events := &fakeOutbox{}
+			svc := &messageService{messageRepo: repo, outbox: events}
+
+			err := svc.MarkMessageAsRead(context.Background(), tt.userID, tt.messageID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			wantRead := tt.wantErr == nil
+			if repo.messages[10].IsRead != wantRead || (len(events.events) == 1) != wantRead {
+				t.Fatalf("read = %v, events = %v", repo.messages[10].IsRead, events.events)
+			}
+		})
+	}
+}
+
+// staleMessageRepo loads every message as unread, like concurrent requests
+// that both read it before either marked it.
+type staleMessageRepo struct {
+	*fakeMessageRepo
+}
+
+func (r *staleMessageRepo) FindByID(ctx context.Context, id uint) (*domain.Message, error) {
+	message, err := r.fakeMessageRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stale := *message
+	stale.IsRead = false
+	return &stale, nil
+}
+
+func TestMarkMessageAsReadConcurrently(t *testing.T) {
+	repo := &staleMessageRepo{&fakeMessageRepo{messages: map[uint]*domain.Message{
+		10: {ID: 10, SenderID: 1, ReceiverID: 2},
+	}}}
+	events := &fakeOutbox{}
+	svc := &messageService{messageRepo: repo, outbox: events}
+
+	for i := 0; i < 2; i++ {
+		if err := svc.MarkMessageAsRead(context.Background(), 2, 10); err != nil {
+			t.Fatalf("MarkMessageAsRead() error = %v", err)
+		}
+	}
+
+	if len(events.events) != 1 {
+		t.Errorf("published %v, want one message.read", events.events)
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+type ModerationService interface {
+	ListFlags(ctx context.Context, status string, limit, offset int) ([]domain.ModerationFlag, int64, error)
+	ReviewFlag(ctx context.Context, reviewerID, flagID uint, decision, clientIP string) error
+}
+
+type moderationService struct {
+	flagRepo    repository.ModerationFlagRepository
+	messageRepo repository.MessageRepository
+	auditRepo   repository.AuditLogRepository
+}
+
+func NewModerationService(
+	flagRepo repository.ModerationFlagRepository,
+	messageRepo repository.MessageRepository,
+	auditRepo repository.AuditLogRepository,
+) ModerationService {
+	return &moderationService{
+		flagRepo:    flagRepo,
+		messageRepo: messageRepo,
+		auditRepo:   auditRepo,
+	}
+}
+
+func (s *moderationService) ListFlags(ctx context.Context, status string, limit, offset int) ([]domain.ModerationFlag, int64, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.flagRepo.List(ctx, status, limit, offset)
+}
+
+// ReviewFlag approves the flagged message or removes it. The decision
+// applies to every pending flag raised on the same message.
+func (s *moderationService) ReviewFlag(ctx context.Context, reviewerID, flagID uint, decision, clientIP string) error {
+	flag, err := s.flagRepo.FindByID(ctx, flagID)
+	if err != nil {
+		return errors.New("flag not found")
+	}
+	if flag.Status != domain.FlagStatusPending {
+		return errors.New("flag already reviewed")
+	}
+
+	status := domain.FlagStatusApproved
+	action := domain.AuditActionFlagApproved
+	if decision == "remove" {
+		status = domain.FlagStatusRemoved
+		action = domain.AuditActionFlagRemoved
+
+		if err := s.messageRepo.Delete(ctx, flag.MessageID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	flag.Status = status
+	flag.ReviewedBy = &reviewerID
+	flag.ReviewedAt = &now
+	if err := s.flagRepo.Update(ctx, flag); err != nil {
+		return err
+	}
+
+	if err := s.flagRepo.UpdateStatusByMessage(ctx, flag.MessageID, status, reviewerID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditRepo, &domain.AuditLog{
+		ActorID:    &reviewerID,
+		Action:     action,
+		TargetType: "message",
+		TargetID:   strconv.FormatUint(uint64(flag.MessageID), 10),
+		IP:         clientIP,
+	}, map[string]interface{}{"flag_id": flag.ID, "filter": flag.Filter})
+
+	return nil
+}
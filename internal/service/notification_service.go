@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+	"github.com/taufiqoo/go-chat/pkg/push"
+)
+
+const (
+	pushPreviewLength = 120
+	pushFlushInterval = time.Second
+	// pushShutdownTimeout bounds sending the collapsed notifications that
+	// are still waiting when the server stops.
+	pushShutdownTimeout = 5 * time.Second
+)
+
+// PresenceChecker reports whether a user has a live connection. It is
+// implemented by the WebSocket hub.
+type PresenceChecker interface {
+	IsOnline(userID uint) bool
+}
+
+type NotificationService interface {
+	RegisterDevice(ctx context.Context, userID uint, req *domain.RegisterDeviceRequest) (*domain.DeviceToken, error)
+	ListDevices(ctx context.Context, userID uint) ([]domain.DeviceToken, error)
+	UnregisterDevice(ctx context.Context, userID, deviceID uint) error
+	MuteConversation(ctx context.Context, userID, otherUserID uint, req *domain.MuteConversationRequest) (*domain.ConversationMute, error)
+	UnmuteConversation(ctx context.Context, userID, otherUserID uint) error
+	ListMutes(ctx context.Context, userID uint) ([]domain.ConversationMuteResponse, error)
+	HandleEvent(ctx context.Context, event eventbus.Event)
+	Run(ctx context.Context)
+}
+
+type notificationService struct {
+	deviceRepo     repository.DeviceTokenRepository
+	muteRepo       repository.ConversationMuteRepository
+	userRepo       repository.UserRepository
+	messageRepo    repository.MessageRepository
+	presence       PresenceChecker
+	provider       push.PushProvider
+	collapseWindow time.Duration
+	showPreview    bool
+
+	mu      sync.Mutex
+	pending map[pushKey]*pendingPush
+}
+
+// pushKey identifies a conversation from the receiver's side.
+type pushKey struct {
+	receiverID uint
+	senderID   uint
+}
+
+// pendingPush collects the messages of one conversation that arrive within
+// the collapse window, so they produce a single notification.
+type pendingPush struct {
+	messageID uint
+	content   string
+	count     int
+	sendAt    time.Time
+}
+
+func NewNotificationService(
+	deviceRepo repository.DeviceTokenRepository,
+	muteRepo repository.ConversationMuteRepository,
+	userRepo repository.UserRepository,
+	messageRepo repository.MessageRepository,
+	presence PresenceChecker,
+	provider push.PushProvider,
+	cfg *config.Config,
+) NotificationService {
+	return &notificationService{
+		deviceRepo:     deviceRepo,
+		muteRepo:       muteRepo,
+		userRepo:       userRepo,
+		messageRepo:    messageRepo,
+		presence:       presence,
+		provider:       provider,
+		collapseWindow: time.Duration(cfg.PushCollapseWindow) * time.Second,
+		showPreview:    cfg.PushShowPreview,
+		pending:        make(map[pushKey]*pendingPush),
+	}
+}
+
+func (s *notificationService) RegisterDevice(ctx context.Context, userID uint, req *domain.RegisterDeviceRequest) (*domain.DeviceToken, error) {
+	device := &domain.DeviceToken{
+		UserID:     userID,
+		Platform:   req.Platform,
+		Token:      req.Token,
+		DeviceName: req.DeviceName,
+	}
+	if err := s.deviceRepo.Upsert(ctx, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *notificationService) ListDevices(ctx context.Context, userID uint) ([]domain.DeviceToken, error) {
+	return s.deviceRepo.FindByUser(ctx, userID)
+}
+
+func (s *notificationService) UnregisterDevice(ctx context.Context, userID, deviceID uint) error {
+	if err := s.deviceRepo.Delete(ctx, userID, deviceID); err != nil {
+		return errors.New("device not found")
+	}
+	return nil
+}
+
+func (s *notificationService) MuteConversation(ctx context.Context, userID, otherUserID uint, req *domain.MuteConversationRequest) (*domain.ConversationMute, error) {
+	if userID == otherUserID {
+		return nil, errors.New("you cannot mute yourself")
+	}
+	if req.MutedUntil != nil && !req.MutedUntil.After(time.Now()) {
+		return nil, errors.New("muted_until must be in the future")
+	}
+	if _, err := s.userRepo.FindByID(ctx, otherUserID); err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	mute := &domain.ConversationMute{
+		UserID:      userID,
+		OtherUserID: otherUserID,
+		MutedUntil:  req.MutedUntil,
+	}
+	if err := s.muteRepo.Upsert(ctx, mute); err != nil {
+		return nil, err
+	}
+	return mute, nil
+}
+
+func (s *notificationService) UnmuteConversation(ctx context.Context, userID, otherUserID uint) error {
+	return s.muteRepo.Delete(ctx, userID, otherUserID)
+}
+
+func (s *notificationService) ListMutes(ctx context.Context, userID uint) ([]domain.ConversationMuteResponse, error) {
+	mutes, err := s.muteRepo.FindByUser(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]domain.ConversationMuteResponse, 0, len(mutes))
+	for _, mute := range mutes {
+		response = append(response, domain.ConversationMuteResponse{
+			User: domain.UserInfo{
+				ID:       mute.OtherUser.ID,
+				Username: mute.OtherUser.Username,
+				IsBot:    mute.OtherUser.IsBot,
+			},
+			MutedUntil: mute.MutedUntil,
+		})
+	}
+	return response, nil
+}
+
+// HandleEvent queues a notification for a message to a user without a live
+// connection. Messages from the same sender are collapsed until the window
+// since the first one has passed.
+func (s *notificationService) HandleEvent(ctx context.Context, event eventbus.Event) {
+	message, ok := event.Payload.(domain.MessageCreatedEvent)
+	if !ok || s.presence.IsOnline(message.ReceiverID) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pushKey{receiverID: message.ReceiverID, senderID: message.SenderID}
+	pending, ok := s.pending[key]
+	if !ok {
+		pending = &pendingPush{sendAt: time.Now().Add(s.collapseWindow)}
+		s.pending[key] = pending
+	}
+	pending.messageID = message.ID
+	pending.content = message.Content
+	pending.count++
+}
+
+func (s *notificationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(pushFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), pushShutdownTimeout)
+			s.flush(flushCtx, time.Time{})
+			cancel()
+			return
+		case <-ticker.C:
+			s.flush(ctx, time.Now())
+		}
+	}
+}
+
+// flush sends the notifications that are due at now, or all of them if now
+// is zero.
+func (s *notificationService) flush(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	due := make(map[pushKey]*pendingPush)
+	for key, pending := range s.pending {
+		if now.IsZero() || !pending.sendAt.After(now) {
+			due[key] = pending
+			delete(s.pending, key)
+		}
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for key, pending := range due {
+		wg.Add(1)
+		go func(key pushKey, pending *pendingPush) {
+			defer wg.Done()
+			s.notify(ctx, key, pending)
+		}(key, pending)
+	}
+	wg.Wait()
+}
+
+func (s *notificationService) notify(ctx context.Context, key pushKey, pending *pendingPush) {
+	// The user may have come back and seen the messages live.
+	if s.presence.IsOnline(key.receiverID) {
+		return
+	}
+
+	muted, err := s.muteRepo.IsMuted(ctx, key.receiverID, key.senderID, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to check conversation mute", "user_id", key.receiverID, "error", err)
+		return
+	}
+	if muted {
+		return
+	}
+
+	devices, err := s.deviceRepo.FindByUser(ctx, key.receiverID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load devices", "user_id", key.receiverID, "error", err)
+		return
+	}
+	if len(devices) == 0 {
+		return
+	}
+
+	// Count every unread message of the conversation, so the notification
+	// replacing an earlier one shows the total.
+	unread, err := s.messageRepo.GetUnreadCountByUser(ctx, key.receiverID, key.senderID)
+	if err != nil {
+		unread = int64(pending.count)
+	}
+	if unread == 0 {
+		return
+	}
+	badge, _ := s.messageRepo.GetUnreadCount(ctx, key.receiverID)
+
+	sender, err := s.userRepo.FindByID(ctx, key.senderID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load push sender", "sender_id", key.senderID, "error", err)
+		return
+	}
+	title := sender.Fullname
+	if title == "" {
+		title = sender.Username
+	}
+
+	body := "New message"
+	if unread > 1 {
+		body = fmt.Sprintf("%d new messages", unread)
+	} else if s.showPreview {
+		body = truncate(pending.content, pushPreviewLength)
+	}
+
+	for _, device := range devices {
+		notification := &push.Notification{
+			Token:       device.Token,
+			Platform:    device.Platform,
+			Title:       title,
+			Body:        body,
+			CollapseKey: "chat-" + strconv.FormatUint(uint64(key.senderID), 10),
+			Badge:       int(badge),
+			Data: map[string]string{
+				"type":       "message",
+				"sender_id":  strconv.FormatUint(uint64(key.senderID), 10),
+				"message_id": strconv.FormatUint(uint64(pending.messageID), 10),
+			},
+		}
+
+		err := s.provider.Send(ctx, notification)
+		if errors.Is(err, push.ErrInvalidToken) {
+			if err := s.deviceRepo.DeleteByToken(ctx, device.Token); err != nil {
+				slog.ErrorContext(ctx, "Failed to remove invalid device", "device_id", device.ID, "error", err)
+			}
+			continue
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to send push", "device_id", device.ID, "user_id", key.receiverID, "error", err)
+		}
+	}
+}
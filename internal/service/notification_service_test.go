@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+	"github.com/taufiqoo/go-chat/pkg/push"
+)
+
+type fakePresence struct {
+	mu     sync.Mutex
+	online map[uint]bool
+}
+
+func (p *fakePresence) IsOnline(userID uint) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.online[userID]
+}
+
+func (p *fakePresence) set(userID uint, online bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.online[userID] = online
+}
+
+type fakeDeviceRepo struct {
+	repository.DeviceTokenRepository
+	mu      sync.Mutex
+	devices []domain.DeviceToken
+	deleted []string
+}
+
+func (r *fakeDeviceRepo) FindByUser(ctx context.Context, userID uint) ([]domain.DeviceToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var devices []domain.DeviceToken
+	for _, device := range r.devices {
+		if device.UserID == userID {
+			devices = append(devices, device)
+		}
+	}
+	return devices, nil
+}
+
+func (r *fakeDeviceRepo) DeleteByToken(ctx context.Context, token string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, token)
+	return nil
+}
+
+type fakeMuteRepo struct {
+	repository.ConversationMuteRepository
+	muted map[uint]uint
+}
+
+func (r *fakeMuteRepo) IsMuted(ctx context.Context, userID, otherUserID uint, now time.Time) (bool, error) {
+	return r.muted[userID] == otherUserID, nil
+}
+
+// fakeUnreadRepo reports unread counts as the number of messages the test
+// sent.
+type fakeUnreadRepo struct {
+	repository.MessageRepository
+	unread int64
+}
+
+func (r *fakeUnreadRepo) GetUnreadCountByUser(ctx context.Context, currentUserID, otherUserID uint) (int64, error) {
+	return r.unread, nil
+}
+
+func (r *fakeUnreadRepo) GetUnreadCount(ctx context.Context, userID uint) (int64, error) {
+	return r.unread, nil
+}
+
+func TestNotificationService(t *testing.T) {
+	const receiver, sender = 1, 2
+
+	tests := []struct {
+		name string
+		// messages are sent to the receiver before the flush.
+		messages    []string
+		onlineAtMsg bool
+		onlineLater bool
+		muted       bool
+		invalid     []string
+		wantSent    []string
+		wantBody    string
+		wantDeleted []string
+	}{
+		{
+			name:     "single message with preview",
+			messages: []string{"hello"},
+			wantSent: []string{"phone", "tablet"},
+			wantBody: "hello",
+		},
+		{
+			name:     "messages collapsed into one notification",
+			messages: []string{"one", "two", "three"},
+			wantSent: []string{"phone", "tablet"},
+			wantBody: "3 new messages",
+		},
+		{
+			name:        "receiver online when the message arrives",
+			messages:    []string{"hello"},
+			onlineAtMsg: true,
+		},
+		{
+			name:        "receiver came back before the flush",
+			messages:    []string{"hello"},
+			onlineLater: true,
+		},
+		{
+			name:     "conversation muted",
+			messages: []string{"hello"},
+			muted:    true,
+		},
+		{
+			name:        "invalid token removed",
+			messages:    []string{"hello"},
+			invalid:     []string{"tablet"},
+			wantSent:    []string{"phone"},
+			wantBody:    "hello",
+			wantDeleted: []string{"tablet"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			presence := &fakePresence{online: map[uint]bool{receiver: tt.onlineAtMsg}}
+			devices := &fakeDeviceRepo{devices: []domain.DeviceToken{
+				{ID: 1, UserID: receiver, Platform: push.PlatformAndroid, Token: "phone"},
+				{ID: 2, UserID: receiver, Platform: push.PlatformIOS, Token: "tablet"},
+				{ID: 3, UserID: sender, Platform: push.PlatformAndroid, Token: "sender-phone"},
+			}}
+			mutes := &fakeMuteRepo{muted: map[uint]uint{}}
+			if tt.muted {
+				mutes.muted[receiver] = sender
+			}
+			users := &fakeUserRepo{users: []*domain.User{
+				{ID: receiver, Username: "bob"},
+				{ID: sender, Username: "alice", Fullname: "Alice"},
+			}}
+			provider := push.NewFake()
+			for _, token := range tt.invalid {
+				provider.Invalidate(token)
+			}
+
+			svc := NewNotificationService(devices, mutes, users, &fakeUnreadRepo{unread: int64(len(tt.messages))}, presence, provider,
+				&config.Config{PushCollapseWindow: 60, PushShowPreview: true}).(*notificationService)
+
+			for i, content := range tt.messages {
+				svc.HandleEvent(context.Background(), eventbus.NewEvent(domain.EventMessageCreated, domain.MessageCreatedEvent{
+					ID:         uint(i + 1),
+					SenderID:   sender,
+					ReceiverID: receiver,
+					Content:    content,
+				}))
+			}
+			if tt.onlineLater {
+				presence.set(receiver, true)
+			}
+
+			// Nothing is sent before the collapse window has passed.
+			svc.flush(context.Background(), time.Now())
+			if sent := provider.Sent(); len(sent) > 0 {
+				t.Fatalf("sent %d notifications inside the collapse window", len(sent))
+			}
+
+			svc.flush(context.Background(), time.Now().Add(time.Minute))
+
+			sent := provider.Sent()
+			tokens := make(map[string]bool)
+			for _, notification := range sent {
+				tokens[notification.Token] = true
+				if notification.Body != tt.wantBody || notification.Title != "Alice" {
+					t.Errorf("notification %q: %q, want %q from Alice", notification.Token, notification.Body, tt.wantBody)
+				}
+				if want := strconv.Itoa(len(tt.messages)); notification.Data["message_id"] != want {
+					t.Errorf("message_id = %s, want the latest message %s", notification.Data["message_id"], want)
+				}
+			}
+			if len(sent) != len(tt.wantSent) {
+				t.Fatalf("sent to %v, want %v", tokens, tt.wantSent)
+			}
+			for _, token := range tt.wantSent {
+				if !tokens[token] {
+					t.Errorf("no notification for %q", token)
+				}
+			}
+			if len(devices.deleted) != len(tt.wantDeleted) || (len(tt.wantDeleted) > 0 && devices.deleted[0] != tt.wantDeleted[0]) {
+				t.Errorf("deleted tokens %v, want %v", devices.deleted, tt.wantDeleted)
+			}
+
+			// Everything was flushed.
+			svc.flush(context.Background(), time.Time{})
+			if len(provider.Sent()) != len(sent) {
+				t.Error("pending notifications left after the flush")
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"github.com/taufiqoo/go-chat/internal/utils"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const oidcRequestTimeout = 10 * time.Second
+
+type OIDCService interface {
+	StartAuth(ctx context.Context, provider string) (*domain.OIDCAuthStart, error)
+	HandleCallback(ctx context.Context, provider string, req *domain.OIDCCallbackRequest) (*domain.UserResponse, error)
+}
+
+type oidcService struct {
+	userRepo     repository.UserRepository
+	identityRepo repository.UserIdentityRepository
+	outbox       OutboxRelay
+	cfg          *config.Config
+	httpClient   *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*oidcProvider
+}
+
+type oidcProvider struct {
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+type oidcClaims struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+	Nonce             string `json:"nonce"`
+}
+
+func NewOIDCService(userRepo repository.UserRepository, identityRepo repository.UserIdentityRepository, outbox OutboxRelay, cfg *config.Config) OIDCService {
+	return &oidcService{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		outbox:       outbox,
+		cfg:          cfg,
+		httpClient:   &http.Client{Timeout: oidcRequestTimeout},
+		providers:    make(map[string]*oidcProvider),
+	}
+}
+
+func (s *oidcService) StartAuth(ctx context.Context, provider string) (*domain.OIDCAuthStart, error) {
+	p, err := s.provider(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := utils.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	stateCookie, err := utils.GenerateOIDCState(utils.OIDCStateClaims{
+		Provider: provider,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+	}, s.cfg.JWTSecret, time.Duration(s.cfg.OIDCStateTTL)*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL := p.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+
+	return &domain.OIDCAuthStart{
+		AuthURL: authURL,
+		State:   stateCookie,
+	}, nil
+}
+
+func (s *oidcService) HandleCallback(ctx context.Context, provider string, req *domain.OIDCCallbackRequest) (*domain.UserResponse, error) {
+	if req.Error != "" {
+		return nil, fmt.Errorf("identity provider returned an error: %s", req.Error)
+	}
+
+	p, err := s.provider(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := utils.ValidateOIDCState(req.StateCookie, s.cfg.JWTSecret)
+	if err != nil || state.Provider != provider || state.State != req.State {
+		return nil, errors.New("invalid or expired login state")
+	}
+
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+
+	token, err := p.oauth2.Exchange(ctx, req.Code, oauth2.VerifierOption(state.Verifier))
+	if err != nil {
+		return nil, errors.New("failed to exchange authorization code")
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("identity provider did not return an id_token")
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, errors.New("invalid id_token")
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, errors.New("invalid id_token claims")
+	}
+	if claims.Nonce != state.Nonce {
+		return nil, errors.New("invalid id_token nonce")
+	}
+
+	user, err := s.resolveUser(ctx, provider, &claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanLogin(user); err != nil {
+		return nil, err
+	}
+
+	jwtToken, err := utils.GenerateToken(user.ID, s.cfg.JWTSecret, s.cfg.JWTExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserResponse{
+		ID:       user.ID,
+		Fullname: user.Fullname,
+		Photo:    user.Photo,
+		Username: user.Username,
+		Email:    user.Email,
+		Token:    jwtToken,
+	}, nil
+}
+
+// resolveUser finds the user linked to the provider subject. Unknown subjects
+// are linked to an existing account when the provider has verified the email
+// address, otherwise a new account is created.
+func (s *oidcService) resolveUser(ctx context.Context, provider string, claims *oidcClaims) (*domain.User, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, provider, claims.Subject)
+	if err == nil {
+		return s.userRepo.FindByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, errors.New("identity provider did not return an email address")
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, claims.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if user != nil && !claims.EmailVerified {
+		return nil, errors.New("email already registered, but the provider has not verified it")
+	}
+
+	identity = &domain.UserIdentity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+
+	if user == nil {
+		return s.createUser(ctx, provider, claims, identity)
+	}
+
+	identity.UserID = user.ID
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createUser creates an account for the claims and links it to the identity
+// in the same transaction, so a failed link leaves no account behind.
+func (s *oidcService) createUser(ctx context.Context, provider string, claims *oidcClaims, identity *domain.UserIdentity) (*domain.User, error) {
+	username, err := s.uniqueUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts created through a provider get an unusable random password.
+	randomPassword, err := utils.RandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	fullname := claims.Name
+	if fullname == "" {
+		fullname = username
+	}
+
+	user := &domain.User{
+		Fullname: truncate(fullname, 50),
+		Photo:    truncate(claims.Picture, 255),
+		Username: username,
+		Email:    claims.Email,
+		Password: hashedPassword,
+		Role:     domain.RoleUser,
+	}
+
+	if err := registerUser(ctx, s.userRepo, s.outbox, user, identity, provider); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_.]+`)
+
+func (s *oidcService) uniqueUsername(ctx context.Context, claims *oidcClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+	base = usernameInvalidChars.ReplaceAllString(strings.ToLower(base), "")
+	if len(base) < 3 {
+		base = "user"
+	}
+	base = truncate(base, 40)
+
+	candidate := base
+	for i := 0; i < 10; i++ {
+		_, err := s.userRepo.FindByUsername(ctx, candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		suffix, err := utils.RandomString(4)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "_" + usernameInvalidChars.ReplaceAllString(strings.ToLower(suffix), "")
+	}
+
+	return "", errors.New("failed to generate a unique username")
+}
+
+func (s *oidcService) provider(ctx context.Context, name string) (*oidcProvider, error) {
+	providerCfg, ok := s.cfg.OIDCProviders[name]
+	if !ok {
+		return nil, errors.New("unknown identity provider")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.providers[name]; ok {
+		return p, nil
+	}
+
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+
+	discovered, err := oidc.NewProvider(ctx, providerCfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover identity provider: %w", err)
+	}
+
+	p := &oidcProvider{
+		oauth2: oauth2.Config{
+			ClientID:     providerCfg.ClientID,
+			ClientSecret: providerCfg.ClientSecret,
+			RedirectURL:  providerCfg.RedirectURL,
+			Endpoint:     discovered.Endpoint(),
+			Scopes:       providerCfg.Scopes,
+		},
+		verifier: discovered.Verifier(&oidc.Config{ClientID: providerCfg.ClientID}),
+	}
+	s.providers[name] = p
+
+	return p, nil
+}
+
+func (s *oidcService) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = oidc.ClientContext(ctx, s.httpClient)
+	return context.WithTimeout(ctx, oidcRequestTimeout)
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
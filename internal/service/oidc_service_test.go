@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+	"gorm.io/gorm"
+)
+
+const (
+	testProvider = "test"
+	testClientID = "go-chat"
+)
+
+// fakeIssuer is an OpenID provider issuing codes for grants registered by
+// the test, checking PKCE on the token endpoint like a real provider.
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]fakeGrant
+}
+
+type fakeGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeIssuer{key: key, grants: make(map[string]fakeGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                f.server.URL,
+			"authorization_endpoint":                f.server.URL + "/authorize",
+			"token_endpoint":                        f.server.URL + "/token",
+			"jwks_uri":                              f.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", f.token)
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	grant, ok := f.grants[r.PostForm.Get("code")]
+	delete(f.grants, r.PostForm.Get("code"))
+	f.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss": f.server.URL,
+		"aud": testClientID,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for key, value := range grant.claims {
+		claims[key] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(f.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// authorize plays the user approving the login started with authURL and
+// returns the code. The nonce of the request is added to claims unless they
+// already hold one.
+func (f *fakeIssuer) authorize(t *testing.T, authURL string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := claims["nonce"]; !ok {
+		claims["nonce"] = u.Query().Get("nonce")
+	}
+
+	code := "code-" + u.Query().Get("state")
+	f.mu.Lock()
+	f.grants[code] = fakeGrant{challenge: u.Query().Get("code_challenge"), claims: claims}
+	f.mu.Unlock()
+	return code
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users           []*domain.User
+	lastSeenUpdates int
+	// identities receives the identities of users created through a
+	// provider.
+	identities *fakeIdentityRepo
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *domain.User) error {
+	user.ID = uint(len(r.users) + 1)
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *fakeUserRepo) CreateWithEvents(ctx context.Context, user *domain.User, events func(*domain.User) ([]domain.OutboxEvent, error)) ([]domain.OutboxEvent, error) {
+	if err := r.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return events(user)
+}
+
+// CreateWithIdentity stores nothing when the identity cannot be created, like
+// the rolled back transaction.
+func (r *fakeUserRepo) CreateWithIdentity(ctx context.Context, user *domain.User, identity *domain.UserIdentity, events func(*domain.User) ([]domain.OutboxEvent, error)) ([]domain.OutboxEvent, error) {
+	user.ID = uint(len(r.users) + 1)
+	identity.UserID = user.ID
+	if err := r.identities.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+	r.users = append(r.users, user)
+	return events(user)
+}
+
+func (r *fakeUserRepo) FindByID(ctx context.Context, id uint) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.ID == id })
+}
+
+func (r *fakeUserRepo) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.Email == email })
+}
+
+func (r *fakeUserRepo) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return r.find(func(u *domain.User) bool { return u.Username == username })
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *domain.User) error {
+	return nil
+}
+
+func (r *fakeUserRepo) UpdateLastSeen(ctx context.Context, id uint, lastSeen time.Time) error {
+	r.lastSeenUpdates++
+	return nil
+}
+
+func (r *fakeUserRepo) find(match func(*domain.User) bool) (*domain.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeIdentityRepo struct {
+	repository.UserIdentityRepository
+	identities []*domain.UserIdentity
+	// err fails every lookup, like a database outage.
+	err error
+	// createErr fails every insert, like a duplicate subject.
+	createErr error
+}
+
+func (r *fakeIdentityRepo) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *fakeIdentityRepo) FindByProviderSubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeOutbox records the types of the events published after a commit.
+type fakeOutbox struct {
+	OutboxRelay
+	events []string
+}
+
+func (o *fakeOutbox) Prepare(events []eventbus.Event) ([]domain.OutboxEvent, error) {
+	rows := make([]domain.OutboxEvent, 0, len(events))
+	for _, event := range events {
+		payload, err := domain.EncodeEvent(event.Payload)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, domain.OutboxEvent{EventID: event.ID, EventType: event.Type, Payload: string(payload)})
+	}
+	return rows, nil
+}
+
+func (o *fakeOutbox) Publish(ctx context.Context, events []eventbus.Event, stored []domain.OutboxEvent) {
+	for _, event := range events {
+		o.events = append(o.events, event.Type)
+	}
+}
+
+func TestOIDCCallback(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	suspendedAt := time.Now()
+
+	tests := []struct {
+		name  string
+		users []*domain.User
+		// identities links subjects to users before the login.
+		identities []*domain.UserIdentity
+		lookupErr  error
+		createErr  error
+		claims     jwt.MapClaims
+		// callback changes the callback request, e.g. to use another
+		// login's state.
+		callback func(t *testing.T, svc OIDCService, req *domain.OIDCCallbackRequest)
+		wantErr  string
+		wantUser uint
+		wantNew  bool
+	}{
+		{
+			name:     "creates a new user",
+			claims:   jwt.MapClaims{"sub": "new", "email": "new@example.com", "email_verified": true, "preferred_username": "newbie"},
+			wantUser: 1,
+			wantNew:  true,
+		},
+		{
+			name:   "state mismatch",
+			claims: jwt.MapClaims{"sub": "s1", "email": "s1@example.com", "email_verified": true},
+			callback: func(t *testing.T, svc OIDCService, req *domain.OIDCCallbackRequest) {
+				req.State = "forged"
+			},
+			wantErr: "invalid or expired login state",
+		},
+		{
+			name:   "PKCE verifier from another login",
+			claims: jwt.MapClaims{"sub": "s2", "email": "s2@example.com", "email_verified": true},
+			callback: func(t *testing.T, svc OIDCService, req *domain.OIDCCallbackRequest) {
+				other, err := svc.StartAuth(context.Background(), testProvider)
+				if err != nil {
+					t.Fatal(err)
+				}
+				u, _ := url.Parse(other.AuthURL)
+				req.State = u.Query().Get("state")
+				req.StateCookie = other.State
+			},
+			wantErr: "failed to exchange authorization code",
+		},
+		{
+			name:    "nonce mismatch",
+			claims:  jwt.MapClaims{"sub": "s3", "email": "s3@example.com", "email_verified": true, "nonce": "forged"},
+			wantErr: "invalid id_token nonce",
+		},
+		{
+			name:     "links an existing account by verified email",
+			users:    []*domain.User{{ID: 1, Username: "alice", Email: "alice@example.com"}},
+			claims:   jwt.MapClaims{"sub": "alice-sub", "email": "alice@example.com", "email_verified": true},
+			wantUser: 1,
+		},
+		{
+			name:    "rejects an unverified email of an existing account",
+			users:   []*domain.User{{ID: 1, Username: "alice", Email: "alice@example.com"}},
+			claims:  jwt.MapClaims{"sub": "alice-sub", "email": "alice@example.com", "email_verified": false},
+			wantErr: "email already registered, but the provider has not verified it",
+		},
+		{
+			name:      "database error is not taken for an unknown subject",
+			lookupErr: errors.New("connection refused"),
+			claims:    jwt.MapClaims{"sub": "new", "email": "new@example.com", "email_verified": true},
+			wantErr:   "connection refused",
+		},
+		{
+			name:      "failed link creates no account",
+			createErr: errors.New("duplicate entry"),
+			claims:    jwt.MapClaims{"sub": "new", "email": "new@example.com", "email_verified": true},
+			wantErr:   "duplicate entry",
+		},
+		{
+			name:       "rejects a suspended account",
+			users:      []*domain.User{{ID: 1, Username: "bob", Email: "bob@example.com", SuspendedAt: &suspendedAt}},
+			identities: []*domain.UserIdentity{{UserID: 1, Provider: testProvider, Subject: "bob-sub"}},
+			claims:     jwt.MapClaims{"sub": "bob-sub", "email": "bob@example.com", "email_verified": true},
+			wantErr:    "account suspended",
+		},
+		{
+			name:       "rejects an account that must reset its password",
+			users:      []*domain.User{{ID: 1, Username: "carol", Email: "carol@example.com", PasswordResetRequired: true}},
+			identities: []*domain.UserIdentity{{UserID: 1, Provider: testProvider, Subject: "carol-sub"}},
+			claims:     jwt.MapClaims{"sub": "carol-sub", "email": "carol@example.com", "email_verified": true},
+			wantErr:    "password reset required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identities := &fakeIdentityRepo{identities: tt.identities, err: tt.lookupErr, createErr: tt.createErr}
+			users := &fakeUserRepo{users: tt.users, identities: identities}
+			events := &fakeOutbox{}
+			svc := NewOIDCService(users, identities, events, &config.Config{
+				JWTSecret:     "secret",
+				JWTExpiration: 1,
+				OIDCStateTTL:  10,
+				OIDCProviders: map[string]config.OIDCProviderConfig{
+					testProvider: {
+						Name:         testProvider,
+						IssuerURL:    issuer.server.URL,
+						ClientID:     testClientID,
+						ClientSecret: "client-secret",
+						RedirectURL:  "http://localhost/callback",
+						Scopes:       []string{"openid", "email", "profile"},
+					},
+				},
+			})
+
+			start, err := svc.StartAuth(context.Background(), testProvider)
+			if err != nil {
+				t.Fatalf("StartAuth: %v", err)
+			}
+			u, _ := url.Parse(start.AuthURL)
+			if u.Query().Get("code_challenge_method") != "S256" {
+				t.Fatalf("auth URL without S256 challenge: %s", start.AuthURL)
+			}
+
+			req := &domain.OIDCCallbackRequest{
+				Code:        issuer.authorize(t, start.AuthURL, tt.claims),
+				State:       u.Query().Get("state"),
+				StateCookie: start.State,
+			}
+			if tt.callback != nil {
+				tt.callback(t, svc, req)
+			}
+
+			resp, err := svc.HandleCallback(context.Background(), testProvider, req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(identities.identities) != len(tt.identities) || len(users.users) != len(tt.users) {
+					t.Fatalf("account created or linked on a failed login")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandleCallback: %v", err)
+			}
+
+			if resp.ID != tt.wantUser || resp.Token == "" {
+				t.Fatalf("logged in as %d (token %q), want %d", resp.ID, resp.Token, tt.wantUser)
+			}
+			if got := len(users.users) - len(tt.users); (got == 1) != tt.wantNew {
+				t.Fatalf("%d users created, want new user %v", got, tt.wantNew)
+			}
+			if tt.wantNew != (len(events.events) == 1) {
+				t.Fatalf("events = %v", events.events)
+			}
+			linked := identities.identities[len(identities.identities)-1]
+			if linked.UserID != tt.wantUser || linked.Subject != tt.claims["sub"] {
+				t.Fatalf("identity = %+v", linked)
+			}
+		})
+	}
+}
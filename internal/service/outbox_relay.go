@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+)
+
+const (
+	outboxBatchSize = 100
+	// outboxFailureDelay is how long an event that cannot be decoded waits
+	// before the relay tries it again.
+	outboxFailureDelay  = time.Hour
+	outboxCleanupPeriod = time.Hour
+)
+
+// EventPublisher publishes events under their existing ID. It is implemented
+// by eventbus.Bus.
+type EventPublisher interface {
+	PublishEvent(ctx context.Context, event eventbus.Event)
+}
+
+// OutboxRelay publishes events stored in the outbox. Events are normally
+// published by the request that stored them, right after the commit; the
+// relay picks up the ones left behind by a crash, so every event is
+// published at least once.
+type OutboxRelay interface {
+	// Prepare returns the outbox rows for events, to be inserted in the
+	// transaction that causes them. The rows are locked for the caller.
+	Prepare(events []eventbus.Event) ([]domain.OutboxEvent, error)
+	// Publish publishes events once their rows are committed and marks the
+	// rows processed.
+	Publish(ctx context.Context, events []eventbus.Event, stored []domain.OutboxEvent)
+	Run(ctx context.Context)
+}
+
+type outboxRelay struct {
+	outboxRepo   repository.OutboxRepository
+	bus          EventPublisher
+	pollInterval time.Duration
+	lease        time.Duration
+	retention    time.Duration
+}
+
+func NewOutboxRelay(outboxRepo repository.OutboxRepository, bus EventPublisher, cfg *config.Config) OutboxRelay {
+	r := &outboxRelay{
+		outboxRepo:   outboxRepo,
+		bus:          bus,
+		pollInterval: time.Duration(cfg.OutboxPollInterval) * time.Second,
+		lease:        time.Duration(cfg.OutboxLease) * time.Second,
+		retention:    time.Duration(cfg.OutboxRetention) * time.Hour,
+	}
+
+	if r.pollInterval <= 0 {
+		r.pollInterval = 5 * time.Second
+	}
+	if r.lease <= 0 {
+		r.lease = 30 * time.Second
+	}
+	if r.retention <= 0 {
+		r.retention = 24 * time.Hour
+	}
+
+	return r
+}
+
+func (r *outboxRelay) Prepare(events []eventbus.Event) ([]domain.OutboxEvent, error) {
+	lockedUntil := time.Now().Add(r.lease)
+	rows := make([]domain.OutboxEvent, 0, len(events))
+	for _, event := range events {
+		payload, err := domain.EncodeEvent(event.Payload)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, domain.OutboxEvent{
+			EventID:     event.ID,
+			EventType:   event.Type,
+			Payload:     string(payload),
+			OccurredAt:  event.OccurredAt,
+			LockedUntil: &lockedUntil,
+		})
+	}
+	return rows, nil
+}
+
+func (r *outboxRelay) Publish(ctx context.Context, events []eventbus.Event, stored []domain.OutboxEvent) {
+	for _, event := range events {
+		r.bus.PublishEvent(ctx, event)
+	}
+
+	ids := make([]uint, 0, len(stored))
+	for _, row := range stored {
+		ids = append(ids, row.ID)
+	}
+
+	// If this fails the relay publishes the events again once the lock
+	// expires.
+	if err := r.outboxRepo.MarkProcessed(ctx, ids); err != nil {
+		slog.ErrorContext(ctx, "Failed to mark outbox events processed", "error", err)
+	}
+}
+
+func (r *outboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(outboxCleanupPeriod)
+	defer cleanup.Stop()
+
+	r.cleanup(ctx)
+	for {
+		r.relayDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-cleanup.C:
+			r.cleanup(ctx)
+		}
+	}
+}
+
+func (r *outboxRelay) relayDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		rows, err := r.outboxRepo.ClaimDue(ctx, now, outboxBatchSize, r.lease)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to claim outbox events", "error", err)
+			return
+		}
+
+		ids := make([]uint, 0, len(rows))
+		for _, row := range rows {
+			payload, err := domain.DecodeEvent(row.EventType, []byte(row.Payload))
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to decode outbox event", "event_id", row.EventID, "error", err)
+				if err := r.outboxRepo.RecordFailure(ctx, row.ID, err.Error(), now.Add(outboxFailureDelay)); err != nil {
+					slog.ErrorContext(ctx, "Failed to record outbox failure", "event_id", row.EventID, "error", err)
+				}
+				continue
+			}
+
+			r.bus.PublishEvent(ctx, eventbus.Event{
+				ID:         row.EventID,
+				Type:       row.EventType,
+				OccurredAt: row.OccurredAt,
+				Payload:    payload,
+			})
+			ids = append(ids, row.ID)
+		}
+
+		if len(ids) > 0 {
+			slog.DebugContext(ctx, "Relayed outbox events", "count", len(ids))
+		}
+		if err := r.outboxRepo.MarkProcessed(ctx, ids); err != nil {
+			slog.ErrorContext(ctx, "Failed to mark outbox events processed", "error", err)
+			return
+		}
+
+		if len(rows) < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// cleanup deletes processed events older than the retention period.
+func (r *outboxRelay) cleanup(ctx context.Context) {
+	deleted, err := r.outboxRepo.DeleteProcessedBefore(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to clean up outbox", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.InfoContext(ctx, "Deleted processed outbox events", "count", deleted)
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+// PresenceProvider reports whether a user currently has a live connection.
+// It is implemented by the WebSocket hub.
+type PresenceProvider interface {
+	IsOnline(userID uint) bool
+}
+
+// MessageNotAllowedError is returned when a block or the receiver's privacy
+// settings forbid the sender from messaging them.
+type MessageNotAllowedError struct {
+	Reason string
+}
+
+func (e *MessageNotAllowedError) Error() string {
+	return e.Reason
+}
+
+type PrivacyService interface {
+	BlockUser(ctx context.Context, blockerID, blockedID uint) error
+	UnblockUser(ctx context.Context, blockerID, blockedID uint) error
+	GetBlockedUsers(ctx context.Context, userID uint) ([]domain.UserBlock, error)
+	IsBlocked(ctx context.Context, userID, otherUserID uint) (bool, error)
+	GetSettings(ctx context.Context, userID uint) (*domain.PrivacySettings, error)
+	UpdateSettings(ctx context.Context, userID uint, req *domain.UpdatePrivacyRequest) (*domain.PrivacySettings, error)
+	CanMessage(ctx context.Context, senderID, receiverID uint) error
+	CanSeeReadReceipts(ctx context.Context, viewerID, ownerID uint) bool
+	GetPresence(ctx context.Context, viewerID, userID uint) (*domain.Presence, error)
+}
+
+type privacyService struct {
+	blockRepo   repository.BlockRepository
+	privacyRepo repository.PrivacyRepository
+	contactRepo repository.ContactRepository
+	userRepo    repository.UserRepository
+	presence    PresenceProvider
+
+	// contactsOnly restricts messaging to accepted contacts for everyone,
+	// regardless of individual privacy settings.
+	contactsOnly bool
+}
+
+func NewPrivacyService(
+	blockRepo repository.BlockRepository,
+	privacyRepo repository.PrivacyRepository,
+	contactRepo repository.ContactRepository,
+	userRepo repository.UserRepository,
+	presence PresenceProvider,
+	contactsOnly bool,
+) PrivacyService {
+	return &privacyService{
+		blockRepo:    blockRepo,
+		privacyRepo:  privacyRepo,
+		contactRepo:  contactRepo,
+		userRepo:     userRepo,
+		presence:     presence,
+		contactsOnly: contactsOnly,
+	}
+}
+
+func (s *privacyService) BlockUser(ctx context.Context, blockerID, blockedID uint) error {
+	if blockerID == blockedID {
+		return errors.New("you cannot block yourself")
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, blockedID); err != nil {
+		return errors.New("user not found")
+	}
+
+	exists, err := s.blockRepo.Exists(ctx, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	if err := s.blockRepo.Create(ctx, &domain.UserBlock{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+	}); err != nil {
+		return err
+	}
+
+	// Blocking someone also ends the contact relationship and any pending
+	// request between the two users.
+	return s.contactRepo.DeleteBetween(ctx, blockerID, blockedID)
+}
+
+func (s *privacyService) UnblockUser(ctx context.Context, blockerID, blockedID uint) error {
+	return s.blockRepo.Delete(ctx, blockerID, blockedID)
+}
+
+func (s *privacyService) GetBlockedUsers(ctx context.Context, userID uint) ([]domain.UserBlock, error) {
+	return s.blockRepo.ListByBlocker(ctx, userID)
+}
+
+func (s *privacyService) IsBlocked(ctx context.Context, userID, otherUserID uint) (bool, error) {
+	return s.blockRepo.IsBlockedEitherWay(ctx, userID, otherUserID)
+}
+
+func (s *privacyService) GetSettings(ctx context.Context, userID uint) (*domain.PrivacySettings, error) {
+	return s.privacyRepo.FindByUserID(ctx, userID)
+}
+
+func (s *privacyService) UpdateSettings(ctx context.Context, userID uint, req *domain.UpdatePrivacyRequest) (*domain.PrivacySettings, error) {
+	settings, err := s.privacyRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.MessagePermission != "" {
+		settings.MessagePermission = req.MessagePermission
+	}
+	if req.LastSeenVisibility != "" {
+		settings.LastSeenVisibility = req.LastSeenVisibility
+	}
+	if req.ReadReceipts != "" {
+		settings.ReadReceipts = req.ReadReceipts
+	}
+
+	if err := s.privacyRepo.Save(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// CanMessage returns a MessageNotAllowedError when the receiver has blocked
+// the sender (or the other way round) or only accepts messages from contacts.
+func (s *privacyService) CanMessage(ctx context.Context, senderID, receiverID uint) error {
+	blocked, err := s.blockRepo.IsBlockedEitherWay(ctx, senderID, receiverID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return &MessageNotAllowedError{Reason: "you cannot message this user"}
+	}
+
+	if s.contactsOnly {
+		if !s.isContact(ctx, senderID, receiverID) {
+			return &MessageNotAllowedError{Reason: "you can only message your contacts"}
+		}
+		return nil
+	}
+
+	settings, err := s.privacyRepo.FindByUserID(ctx, receiverID)
+	if err != nil {
+		return err
+	}
+
+	if !s.allowed(ctx, settings.MessagePermission, senderID, receiverID) {
+		return &MessageNotAllowedError{Reason: "this user only accepts messages from contacts"}
+	}
+
+	return nil
+}
+
+func (s *privacyService) CanSeeReadReceipts(ctx context.Context, viewerID, ownerID uint) bool {
+	settings, err := s.privacyRepo.FindByUserID(ctx, ownerID)
+	if err != nil {
+		return false
+	}
+	return s.allowed(ctx, settings.ReadReceipts, viewerID, ownerID)
+}
+
+// GetPresence returns the online state and last-seen time of a user as seen
+// by the viewer. Blocked users always appear offline with no last-seen time.
+func (s *privacyService) GetPresence(ctx context.Context, viewerID, userID uint) (*domain.Presence, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	presence := &domain.Presence{UserID: user.ID}
+	if viewerID == userID {
+		presence.Online = s.presence.IsOnline(userID)
+		presence.LastSeenAt = user.LastSeenAt
+		return presence, nil
+	}
+
+	blocked, err := s.blockRepo.IsBlockedEitherWay(ctx, viewerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return presence, nil
+	}
+
+	settings, err := s.privacyRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.allowed(ctx, settings.LastSeenVisibility, viewerID, userID) {
+		presence.Online = s.presence.IsOnline(userID)
+		presence.LastSeenAt = user.LastSeenAt
+	}
+
+	return presence, nil
+}
+
+func (s *privacyService) allowed(ctx context.Context, visibility string, viewerID, ownerID uint) bool {
+	switch visibility {
+	case domain.VisibilityNobody:
+		return false
+	case domain.VisibilityContacts:
+		return s.isContact(ctx, viewerID, ownerID)
+	default:
+		return true
+	}
+}
+
+func (s *privacyService) isContact(ctx context.Context, userID, otherUserID uint) bool {
+	ok, err := s.contactRepo.AreContacts(ctx, userID, otherUserID)
+	return err == nil && ok
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+)
+
+type ReportService interface {
+	CreateReport(ctx context.Context, reporterID uint, req *domain.CreateReportRequest) (*domain.Report, error)
+	ListReports(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, int64, error)
+	GetReport(ctx context.Context, id uint) (*domain.Report, error)
+	TriageReport(ctx context.Context, moderatorID, reportID uint, clientIP string) (*domain.Report, error)
+	ResolveReport(ctx context.Context, moderatorID, reportID uint, req *domain.ResolveReportRequest, clientIP string) (*domain.Report, error)
+}
+
+type reportService struct {
+	reportRepo   repository.ReportRepository
+	messageRepo  repository.MessageRepository
+	userRepo     repository.UserRepository
+	auditRepo    repository.AuditLogRepository
+	adminService AdminService
+}
+
+func NewReportService(
+	reportRepo repository.ReportRepository,
+	messageRepo repository.MessageRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditLogRepository,
+	adminService AdminService,
+) ReportService {
+	return &reportService{
+		reportRepo:   reportRepo,
+		messageRepo:  messageRepo,
+		userRepo:     userRepo,
+		auditRepo:    auditRepo,
+		adminService: adminService,
+	}
+}
+
+type messageSnapshot struct {
+	MessageID  uint      `json:"message_id"`
+	SenderID   uint      `json:"sender_id"`
+	Sender     string    `json:"sender"`
+	ReceiverID uint      `json:"receiver_id"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type userSnapshot struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Fullname string `json:"fullname"`
+	Photo    string `json:"photo"`
+}
+
+func (s *reportService) CreateReport(ctx context.Context, reporterID uint, req *domain.CreateReportRequest) (*domain.Report, error) {
+	snapshot, err := s.snapshot(ctx, reporterID, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.reportRepo.HasOpenReport(ctx, reporterID, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("you already reported this and it is being reviewed")
+	}
+
+	report := &domain.Report{
+		ReporterID: reporterID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+		Snapshot:   snapshot,
+		Status:     domain.ReportStatusOpen,
+	}
+
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// snapshot captures the reported content. Users can only report messages
+// from conversations they are part of.
+func (s *reportService) snapshot(ctx context.Context, reporterID uint, targetType string, targetID uint) (string, error) {
+	var data interface{}
+
+	switch targetType {
+	case domain.ReportTargetMessage:
+		message, err := s.messageRepo.FindByID(ctx, targetID)
+		if err != nil || (message.SenderID != reporterID && message.ReceiverID != reporterID) {
+			return "", errors.New("message not found")
+		}
+		if message.SenderID == reporterID {
+			return "", errors.New("you cannot report your own message")
+		}
+		data = messageSnapshot{
+			MessageID:  message.ID,
+			SenderID:   message.SenderID,
+			Sender:     message.Sender.Username,
+			ReceiverID: message.ReceiverID,
+			Content:    message.Content,
+			CreatedAt:  message.CreatedAt,
+		}
+
+	case domain.ReportTargetUser:
+		if targetID == reporterID {
+			return "", errors.New("you cannot report yourself")
+		}
+		user, err := s.userRepo.FindByID(ctx, targetID)
+		if err != nil {
+			return "", errors.New("user not found")
+		}
+		data = userSnapshot{
+			UserID:   user.ID,
+			Username: user.Username,
+			Fullname: user.Fullname,
+			Photo:    user.Photo,
+		}
+
+	default:
+		return "", errors.New("invalid report target")
+	}
+
+	snapshot, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(snapshot), nil
+}
+
+func (s *reportService) ListReports(ctx context.Context, filter domain.ReportFilter) ([]domain.Report, int64, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.reportRepo.List(ctx, filter)
+}
+
+func (s *reportService) GetReport(ctx context.Context, id uint) (*domain.Report, error) {
+	report, err := s.reportRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("report not found")
+	}
+	return report, nil
+}
+
+// TriageReport assigns an open report to the moderator handling it.
+func (s *reportService) TriageReport(ctx context.Context, moderatorID, reportID uint, clientIP string) (*domain.Report, error) {
+	report, err := s.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != domain.ReportStatusOpen {
+		return nil, errors.New("report is not open")
+	}
+
+	report.Status = domain.ReportStatusInReview
+	report.AssignedTo = &moderatorID
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, moderatorID, domain.AuditActionReportTriaged, report, clientIP, nil)
+	return report, nil
+}
+
+func (s *reportService) ResolveReport(ctx context.Context, moderatorID, reportID uint, req *domain.ResolveReportRequest, clientIP string) (*domain.Report, error) {
+	report, err := s.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status == domain.ReportStatusResolved || report.Status == domain.ReportStatusDismissed {
+		return nil, errors.New("report already closed")
+	}
+
+	action := req.Action
+	if action == "" || req.Status == domain.ReportStatusDismissed {
+		action = domain.ReportActionNone
+	}
+
+	if err := s.applyAction(ctx, moderatorID, report, action, req.Note, clientIP); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report.Status = req.Status
+	report.Resolution = action
+	report.ResolutionNote = req.Note
+	report.ResolvedBy = &moderatorID
+	report.ResolvedAt = &now
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return nil, err
+	}
+
+	auditAction := domain.AuditActionReportResolved
+	if req.Status == domain.ReportStatusDismissed {
+		auditAction = domain.AuditActionReportDismissed
+	}
+	s.audit(ctx, moderatorID, auditAction, report, clientIP, map[string]interface{}{
+		"action": action,
+		"note":   req.Note,
+	})
+
+	return report, nil
+}
+
+func (s *reportService) applyAction(ctx context.Context, moderatorID uint, report *domain.Report, action, note, clientIP string) error {
+	switch action {
+	case domain.ReportActionDeleteMessage:
+		if report.TargetType != domain.ReportTargetMessage {
+			return errors.New("only message reports can delete a message")
+		}
+		if err := s.messageRepo.Delete(ctx, report.TargetID); err != nil {
+			return err
+		}
+		recordAudit(ctx, s.auditRepo, &domain.AuditLog{
+			ActorID:    &moderatorID,
+			Action:     domain.AuditActionMessageDeleted,
+			TargetType: "message",
+			TargetID:   strconv.FormatUint(uint64(report.TargetID), 10),
+			IP:         clientIP,
+		}, map[string]interface{}{"report_id": report.ID})
+
+	case domain.ReportActionSuspendUser:
+		userID, err := s.reportedUserID(report)
+		if err != nil {
+			return err
+		}
+		reason := "report #" + strconv.FormatUint(uint64(report.ID), 10)
+		if note != "" {
+			reason += ": " + note
+		}
+		// Earlier reports may already have had the account suspended.
+		err = s.adminService.SuspendUser(ctx, moderatorID, userID, reason, clientIP)
+		if err != nil && !errors.Is(err, ErrAlreadySuspended) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reportedUserID returns the account behind the report: the user itself or
+// the sender of the reported message, taken from the snapshot so it still
+// works after the message has been deleted.
+func (s *reportService) reportedUserID(report *domain.Report) (uint, error) {
+	if report.TargetType == domain.ReportTargetUser {
+		return report.TargetID, nil
+	}
+
+	var snapshot messageSnapshot
+	if err := json.Unmarshal([]byte(report.Snapshot), &snapshot); err != nil {
+		return 0, errors.New("invalid report snapshot")
+	}
+	return snapshot.SenderID, nil
+}
+
+func (s *reportService) audit(ctx context.Context, actorID uint, action string, report *domain.Report, clientIP string, metadata map[string]interface{}) {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["target_type"] = report.TargetType
+	metadata["target_id"] = report.TargetID
+
+	recordAudit(ctx, s.auditRepo, &domain.AuditLog{
+		ActorID:    &actorID,
+		Action:     action,
+		TargetType: "report",
+		TargetID:   strconv.FormatUint(uint64(report.ID), 10),
+		IP:         clientIP,
+	}, metadata)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"gorm.io/gorm"
+)
+
+type fakeReportRepo struct {
+	repository.ReportRepository
+	reports map[uint]*domain.Report
+}
+
+func (r *fakeReportRepo) FindByID(ctx context.Context, id uint) (*domain.Report, error) {
+	if report, ok := r.reports[id]; ok {
+		return report, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeReportRepo) Update(ctx context.Context, report *domain.Report) error {
+	return nil
+}
+
+func TestResolveReportSuspendsAlreadySuspendedUser(t *testing.T) {
+	suspendedAt := time.Now()
+	users := &fakeUserRepo{users: []*domain.User{
+		{ID: 1, Role: domain.RoleModerator},
+		{ID: 2, Role: domain.RoleUser, SuspendedAt: &suspendedAt},
+	}}
+	reports := &fakeReportRepo{reports: map[uint]*domain.Report{
+		10: {ID: 10, TargetType: domain.ReportTargetUser, TargetID: 2, Status: domain.ReportStatusInReview},
+	}}
+	audit := &fakeAuditRepo{}
+	admin := NewAdminService(users, nil, audit, &fakeConnections{}, nil)
+	svc := NewReportService(reports, nil, users, audit, admin)
+
+	// An earlier report already had the account suspended.
+	report, err := svc.ResolveReport(context.Background(), 1, 10, &domain.ResolveReportRequest{
+		Status: domain.ReportStatusResolved,
+		Action: domain.ReportActionSuspendUser,
+	}, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("ResolveReport() error = %v", err)
+	}
+	if report.Status != domain.ReportStatusResolved || report.Resolution != domain.ReportActionSuspendUser {
+		t.Errorf("report = %s/%s, want resolved with suspend_user", report.Status, report.Resolution)
+	}
+	if !users.users[1].SuspendedAt.Equal(suspendedAt) {
+		t.Error("existing suspension was replaced")
+	}
+}
@@ -1,40 +1,85 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/taufiqoo/go-chat/internal/config"
 	"github.com/taufiqoo/go-chat/internal/domain"
 	"github.com/taufiqoo/go-chat/internal/repository"
 	"github.com/taufiqoo/go-chat/internal/utils"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+	"github.com/taufiqoo/go-chat/pkg/mailer"
 )
 
 type UserService interface {
-	Register(req *domain.UserRegisterRequest) (*domain.UserResponse, error)
-	Login(req *domain.UserLoginRequest) (*domain.UserResponse, error)
-	GetUserByID(id uint) (*domain.User, error)
-	GetAllUsers() ([]domain.User, error)
+	Register(ctx context.Context, req *domain.UserRegisterRequest) (*domain.UserResponse, error)
+	Login(ctx context.Context, req *domain.UserLoginRequest, clientIP string) (*domain.UserResponse, error)
+	GetUserByID(ctx context.Context, id uint) (*domain.User, error)
+	GetAllUsers(ctx context.Context, viewerID uint, query string) ([]domain.User, error)
+	UpdateLastSeen(ctx context.Context, userID uint) error
+	RecordActivity(ctx context.Context, user *domain.User)
+	UnlockAccount(ctx context.Context, token, clientIP string) error
+	AdminUnlock(ctx context.Context, actorID, userID uint, clientIP string) error
+	ForcePasswordReset(ctx context.Context, actorID, userID uint, clientIP string) error
+	ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest, clientIP string) error
 }
 
+const passwordResetTokenTTL = 24 * time.Hour
+
+// lastSeenResolution is how stale last_seen_at may get while the user is
+// active, so authenticated requests do not each write it.
+const lastSeenResolution = time.Minute
+
 type userService struct {
-	userRepo repository.UserRepository
-	cfg      *config.Config
+	userRepo      repository.UserRepository
+	loginAttempts repository.LoginAttemptRepository
+	auditRepo     repository.AuditLogRepository
+	mailer        mailer.Mailer
+	outbox        OutboxRelay
+	connections   ConnectionManager
+	cfg           *config.Config
+
+	// dummyHash is compared against when the email is unknown so a failed
+	// login takes the same time whether or not the account exists.
+	dummyHash string
 }
 
-func NewUserService(userRepo repository.UserRepository, cfg *config.Config) UserService {
+func NewUserService(
+	userRepo repository.UserRepository,
+	loginAttempts repository.LoginAttemptRepository,
+	auditRepo repository.AuditLogRepository,
+	mailer mailer.Mailer,
+	outbox OutboxRelay,
+	connections ConnectionManager,
+	cfg *config.Config,
+) UserService {
+	dummyHash, _ := utils.HashPassword("dummy-password-for-timing")
+
 	return &userService{
-		userRepo: userRepo,
-		cfg:      cfg,
+		userRepo:      userRepo,
+		loginAttempts: loginAttempts,
+		auditRepo:     auditRepo,
+		mailer:        mailer,
+		outbox:        outbox,
+		connections:   connections,
+		cfg:           cfg,
+		dummyHash:     dummyHash,
 	}
 }
 
-func (u *userService) Register(req *domain.UserRegisterRequest) (*domain.UserResponse, error) {
-	existingUser, _ := u.userRepo.FindByEmail(req.Email)
+func (u *userService) Register(ctx context.Context, req *domain.UserRegisterRequest) (*domain.UserResponse, error) {
+	existingUser, _ := u.userRepo.FindByEmail(ctx, req.Email)
 	if existingUser != nil {
 		return nil, errors.New("email already registered")
 	}
 
-	existingUser, _ = u.userRepo.FindByUsername(req.Username)
+	existingUser, _ = u.userRepo.FindByUsername(ctx, req.Username)
 	if existingUser != nil {
 		return nil, errors.New("username already taken")
 	}
@@ -49,9 +94,10 @@ func (u *userService) Register(req *domain.UserRegisterRequest) (*domain.UserRes
 		Username: req.Username,
 		Email:    req.Email,
 		Password: hashedPassword,
+		Role:     domain.RoleUser,
 	}
 
-	if err := u.userRepo.Create(user); err != nil {
+	if err := registerUser(ctx, u.userRepo, u.outbox, user, nil, "password"); err != nil {
 		return nil, err
 	}
 
@@ -65,20 +111,35 @@ func (u *userService) Register(req *domain.UserRegisterRequest) (*domain.UserRes
 		Fullname: user.Fullname,
 		Username: user.Username,
 		Email:    user.Email,
+		Role:     user.Role,
 		Token:    token,
 	}, nil
 }
 
-func (u *userService) Login(req *domain.UserLoginRequest) (*domain.UserResponse, error) {
-	user, err := u.userRepo.FindByEmail(req.Email)
+func (u *userService) Login(ctx context.Context, req *domain.UserLoginRequest, clientIP string) (*domain.UserResponse, error) {
+	if err := u.checkLoginAllowed(ctx, req.Email, clientIP); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
+		utils.CheckPassword(req.Password, u.dummyHash)
+		u.recordLoginFailure(ctx, req.Email, clientIP, nil)
 		return nil, errors.New("invalid email or password")
 	}
 
 	if !utils.CheckPassword(req.Password, user.Password) {
+		u.recordLoginFailure(ctx, req.Email, clientIP, user)
 		return nil, errors.New("invalid email or password")
 	}
 
+	u.recordLoginSuccess(ctx, req.Email)
+
+	// Only reveal the account state once the password has been verified.
+	if err := checkCanLogin(user); err != nil {
+		return nil, err
+	}
+
 	// Generate JWT token
 	token, err := utils.GenerateToken(user.ID, u.cfg.JWTSecret, u.cfg.JWTExpiration)
 	if err != nil {
@@ -90,14 +151,191 @@ func (u *userService) Login(req *domain.UserLoginRequest) (*domain.UserResponse,
 		Fullname: user.Fullname,
 		Username: user.Username,
 		Email:    user.Email,
+		Role:     user.Role,
 		Token:    token,
 	}, nil
 }
 
-func (u *userService) GetUserByID(id uint) (*domain.User, error) {
-	return u.userRepo.FindByID(id)
+func (u *userService) GetUserByID(ctx context.Context, id uint) (*domain.User, error) {
+	return u.userRepo.FindByID(ctx, id)
+}
+
+func (u *userService) GetAllUsers(ctx context.Context, viewerID uint, query string) ([]domain.User, error) {
+	return u.userRepo.SearchVisible(ctx, viewerID, query)
+}
+
+func (u *userService) UpdateLastSeen(ctx context.Context, userID uint) error {
+	return u.userRepo.UpdateLastSeen(ctx, userID, time.Now())
+}
+
+// RecordActivity updates the last seen time of a user making an authenticated
+// request, whatever the transport. Live connections also update it when they
+// end.
+func (u *userService) RecordActivity(ctx context.Context, user *domain.User) {
+	now := time.Now()
+	if user.LastSeenAt != nil && now.Sub(*user.LastSeenAt) < lastSeenResolution {
+		return
+	}
+	if err := u.userRepo.UpdateLastSeen(ctx, user.ID, now); err != nil {
+		slog.ErrorContext(ctx, "Failed to update last seen", "user_id", user.ID, "error", err)
+		return
+	}
+	user.LastSeenAt = &now
+}
+
+func (u *userService) UnlockAccount(ctx context.Context, token, clientIP string) error {
+	userID, err := utils.ValidateActionToken(token, utils.TokenPurposeUnlock, u.cfg.JWTSecret)
+	if err != nil {
+		return errors.New("invalid or expired unlock link")
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return errors.New("invalid or expired unlock link")
+	}
+
+	return u.unlock(ctx, user, user.ID, clientIP)
+}
+
+func (u *userService) AdminUnlock(ctx context.Context, actorID, userID uint, clientIP string) error {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	return u.unlock(ctx, user, actorID, clientIP)
+}
+
+func (u *userService) ForcePasswordReset(ctx context.Context, actorID, userID uint, clientIP string) error {
+	user, err := loadActionTarget(ctx, u.userRepo, actorID, userID)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordResetRequired = true
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Tokens issued before stop working at the next request; live
+	// connections are ended now.
+	u.connections.DisconnectUser(user.ID, "password reset required")
+
+	recordAudit(ctx, u.auditRepo, &domain.AuditLog{
+		ActorID:    &actorID,
+		Action:     domain.AuditActionPasswordResetForced,
+		TargetType: "user",
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+		IP:         clientIP,
+	}, nil)
+
+	go u.sendPasswordResetEmail(user)
+
+	return nil
+}
+
+func (u *userService) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest, clientIP string) error {
+	userID, err := utils.ValidateActionToken(req.Token, utils.TokenPurposePasswordReset, u.cfg.JWTSecret)
+	if err != nil {
+		return errors.New("invalid or expired reset link")
+	}
+
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil || !user.PasswordResetRequired {
+		return errors.New("invalid or expired reset link")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	user.PasswordResetRequired = false
+	if err := u.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if err := u.loginAttempts.Unblock(ctx, emailAttemptKey(user.Email)); err != nil {
+		slog.ErrorContext(ctx, "Failed to clear login block after password reset", "user_id", user.ID, "error", err)
+	}
+
+	recordAudit(ctx, u.auditRepo, &domain.AuditLog{
+		ActorID:    &user.ID,
+		Action:     domain.AuditActionPasswordReset,
+		TargetType: "user",
+		TargetID:   strconv.FormatUint(uint64(user.ID), 10),
+		IP:         clientIP,
+	}, nil)
+
+	return nil
 }
 
-func (u *userService) GetAllUsers() ([]domain.User, error) {
-	return u.userRepo.GetAll()
+func (u *userService) sendPasswordResetEmail(user *domain.User) {
+	token, err := utils.GenerateActionToken(utils.TokenPurposePasswordReset, user.ID, u.cfg.JWTSecret, passwordResetTokenTTL)
+	if err != nil {
+		slog.Error("Failed to generate password reset token", "user_id", user.ID, "error", err)
+		return
+	}
+
+	err = u.mailer.Send(&mailer.Mail{
+		To:      user.Email,
+		Subject: "Please reset your password",
+		TextBody: fmt.Sprintf("Hi %s,\n\nAn administrator requires you to choose a new password before you can log in again.\n"+
+			"Send your new password together with this token to %s/api/v1/auth/password/reset:\n\n%s\n\nThe token expires in 24 hours.\n",
+			user.Fullname, strings.TrimSuffix(u.cfg.AppBaseURL, "/"), token),
+	})
+	if err != nil {
+		slog.Error("Failed to send password reset email", "user_id", user.ID, "error", err)
+	}
+}
+
+// registerUser creates the user together with its user.registered event and,
+// for accounts created through a provider, its identity, and publishes the
+// event once all of them are committed.
+func registerUser(ctx context.Context, userRepo repository.UserRepository, outbox OutboxRelay, user *domain.User, identity *domain.UserIdentity, provider string) error {
+	var events []eventbus.Event
+	prepare := func(user *domain.User) ([]domain.OutboxEvent, error) {
+		events = []eventbus.Event{
+			eventbus.NewEvent(domain.EventUserRegistered, userRegisteredEvent(user, provider)),
+		}
+		return outbox.Prepare(events)
+	}
+
+	var stored []domain.OutboxEvent
+	var err error
+	if identity != nil {
+		stored, err = userRepo.CreateWithIdentity(ctx, user, identity, prepare)
+	} else {
+		stored, err = userRepo.CreateWithEvents(ctx, user, prepare)
+	}
+	if err != nil {
+		return err
+	}
+
+	outbox.Publish(context.WithoutCancel(ctx), events, stored)
+	return nil
+}
+
+func userRegisteredEvent(user *domain.User, provider string) domain.UserRegisteredEvent {
+	return domain.UserRegisteredEvent{
+		ID:        user.ID,
+		Username:  user.Username,
+		Fullname:  user.Fullname,
+		Email:     user.Email,
+		Provider:  provider,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// checkCanLogin rejects accounts that may not start a session, whatever the
+// login method.
+func checkCanLogin(user *domain.User) error {
+	if user.IsSuspended() {
+		return errors.New("account suspended")
+	}
+	if user.PasswordResetRequired {
+		return errors.New("password reset required, check your email for instructions")
+	}
+	return nil
 }
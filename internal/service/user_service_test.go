@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/pkg/mailer"
+)
+
+func TestRecordActivity(t *testing.T) {
+	ago := func(d time.Duration) *time.Time {
+		at := time.Now().Add(-d)
+		return &at
+	}
+
+	tests := []struct {
+		name       string
+		lastSeenAt *time.Time
+		wantUpdate bool
+	}{
+		{name: "never seen", wantUpdate: true},
+		{name: "seen long ago", lastSeenAt: ago(time.Hour), wantUpdate: true},
+		{name: "seen just now", lastSeenAt: ago(time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{}
+			svc := &userService{userRepo: users}
+			user := &domain.User{ID: 1, LastSeenAt: tt.lastSeenAt}
+
+			svc.RecordActivity(context.Background(), user)
+			svc.RecordActivity(context.Background(), user)
+
+			want := 0
+			if tt.wantUpdate {
+				want = 1
+			}
+			if users.lastSeenUpdates != want {
+				t.Errorf("last seen updates = %d, want %d", users.lastSeenUpdates, want)
+			}
+		})
+	}
+}
+
+type fakeMailer struct {
+	sent chan *mailer.Mail
+}
+
+func (m *fakeMailer) Send(mail *mailer.Mail) error {
+	m.sent <- mail
+	return nil
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	tests := []struct {
+		name      string
+		actor     string
+		target    string
+		self      bool
+		wantError error
+	}{
+		{name: "admin resets user", actor: domain.RoleAdmin, target: domain.RoleUser},
+		{name: "admin cannot reset admin", actor: domain.RoleAdmin, target: domain.RoleAdmin, wantError: ErrInsufficientRole},
+		{name: "moderator cannot reset admin", actor: domain.RoleModerator, target: domain.RoleAdmin, wantError: ErrInsufficientRole},
+		{name: "admin cannot reset themselves", actor: domain.RoleAdmin, self: true, wantError: ErrOwnAccount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor := &domain.User{ID: 1, Role: tt.actor}
+			target := &domain.User{ID: 2, Role: tt.target, Email: "user@example.com"}
+			users := &fakeUserRepo{users: []*domain.User{actor, target}}
+			connections := &fakeConnections{}
+			mail := &fakeMailer{sent: make(chan *mailer.Mail, 1)}
+			svc := NewUserService(users, nil, &fakeAuditRepo{}, mail, nil, connections, &config.Config{JWTSecret: "secret"})
+
+			if tt.self {
+				target = actor
+			}
+			err := svc.ForcePasswordReset(context.Background(), actor.ID, target.ID, "127.0.0.1")
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("ForcePasswordReset() error = %v, want %v", err, tt.wantError)
+			}
+
+			if tt.wantError != nil {
+				if target.PasswordResetRequired || len(connections.disconnected) > 0 {
+					t.Error("rejected reset changed the target")
+				}
+				return
+			}
+			if !target.PasswordResetRequired {
+				t.Error("reset not required")
+			}
+			if len(connections.disconnected) != 1 || connections.disconnected[0] != target.ID {
+				t.Errorf("disconnected %v, want the target's live connections ended", connections.disconnected)
+			}
+			if sent := <-mail.sent; sent.To != target.Email {
+				t.Errorf("reset mail sent to %q", sent.To)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+const (
+	webhookBatchSize = 20
+	// webhookResponseLimit caps how much of a response body is logged.
+	webhookResponseLimit = 1024
+)
+
+func (s *webhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *webhookService) dispatchDue(ctx context.Context) {
+	// A claimed delivery is locked long enough for the request to time out,
+	// after that another worker may pick it up again.
+	lease := 2 * s.timeout
+
+	for ctx.Err() == nil {
+		deliveries, err := s.deliveryRepo.ClaimDue(ctx, time.Now(), webhookBatchSize, lease)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to claim webhook deliveries", "error", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func(delivery *domain.WebhookDelivery) {
+				defer wg.Done()
+				s.deliver(ctx, delivery)
+			}(&deliveries[i])
+		}
+		wg.Wait()
+
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+func (s *webhookService) deliver(ctx context.Context, delivery *domain.WebhookDelivery) {
+	attempt := s.send(ctx, delivery)
+	if ctx.Err() != nil {
+		// Shutting down: the lock expires and the delivery is sent again.
+		return
+	}
+
+	delivery.Attempts++
+	delivery.ResponseStatus = attempt.StatusCode
+
+	if attempt.Error == "" {
+		now := time.Now()
+		delivery.Status = domain.DeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = attempt.Error
+		if delivery.Attempts >= s.maxAttempts {
+			delivery.Status = domain.DeliveryStatusDead
+			slog.WarnContext(ctx, "Webhook delivery failed too often, giving up",
+				"delivery_id", delivery.ID, "webhook_id", delivery.WebhookID, "attempts", delivery.Attempts, "error", attempt.Error)
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(s.backoff(delivery.Attempts))
+		}
+	}
+
+	if err := s.deliveryRepo.RecordAttempt(ctx, delivery, attempt); err != nil {
+		slog.ErrorContext(ctx, "Failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+func (s *webhookService) send(ctx context.Context, delivery *domain.WebhookDelivery) *domain.WebhookDeliveryAttempt {
+	attempt := &domain.WebhookDeliveryAttempt{DeliveryID: delivery.ID}
+	start := time.Now()
+	defer func() {
+		attempt.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-chat-webhooks/1.0")
+	req.Header.Set("X-Webhook-ID", delivery.EventID)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Signature", SignWebhookPayload(delivery.Webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	attempt.StatusCode = resp.StatusCode
+	attempt.ResponseBody = string(body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = fmt.Sprintf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return attempt
+}
+
+// backoff doubles the delay after each failed attempt, up to retryMax.
+func (s *webhookService) backoff(attempts int) time.Duration {
+	delay := s.retryBase
+	for i := 1; i < attempts && delay < s.retryMax; i++ {
+		delay *= 2
+	}
+	if delay > s.retryMax {
+		delay = s.retryMax
+	}
+	return delay
+}
+
+// SignWebhookPayload returns the X-Webhook-Signature header value:
+// "t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">".
+// Receivers should recompute it and reject old timestamps.
+func SignWebhookPayload(secret string, timestamp int64, body string) string {
+	ts := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "." + body))
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/internal/repository"
+	"github.com/taufiqoo/go-chat/internal/utils"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, actorID uint, req *domain.CreateWebhookRequest, clientIP string) (*domain.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	GetWebhook(ctx context.Context, id uint) (*domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, actorID, id uint, req *domain.UpdateWebhookRequest, clientIP string) (*domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, actorID, id uint, clientIP string) error
+	ListDeliveries(ctx context.Context, webhookID uint, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, int64, error)
+	GetDelivery(ctx context.Context, webhookID, deliveryID uint) (*domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, actorID, webhookID, deliveryID uint, clientIP string) (*domain.WebhookDelivery, error)
+	// HandleEvent queues the event for every webhook subscribed to it.
+	HandleEvent(ctx context.Context, event eventbus.Event)
+	// Run sends queued deliveries until ctx is cancelled.
+	Run(ctx context.Context)
+}
+
+type webhookService struct {
+	webhookRepo  repository.WebhookRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+	auditRepo    repository.AuditLogRepository
+	httpClient   *http.Client
+
+	maxAttempts  int
+	retryBase    time.Duration
+	retryMax     time.Duration
+	timeout      time.Duration
+	pollInterval time.Duration
+
+	// wake makes Run look for due deliveries before the next poll.
+	wake chan struct{}
+}
+
+func NewWebhookService(
+	webhookRepo repository.WebhookRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	auditRepo repository.AuditLogRepository,
+	cfg *config.Config,
+) WebhookService {
+	s := &webhookService{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		auditRepo:    auditRepo,
+		maxAttempts:  cfg.WebhookMaxAttempts,
+		retryBase:    time.Duration(cfg.WebhookRetryBase) * time.Second,
+		retryMax:     time.Duration(cfg.WebhookRetryMax) * time.Second,
+		timeout:      time.Duration(cfg.WebhookTimeout) * time.Second,
+		pollInterval: time.Duration(cfg.WebhookPollInterval) * time.Second,
+		wake:         make(chan struct{}, 1),
+	}
+
+	if s.maxAttempts <= 0 {
+		s.maxAttempts = 8
+	}
+	if s.retryBase <= 0 {
+		s.retryBase = 30 * time.Second
+	}
+	if s.retryMax < s.retryBase {
+		s.retryMax = s.retryBase
+	}
+	if s.timeout <= 0 {
+		s.timeout = 10 * time.Second
+	}
+	if s.pollInterval <= 0 {
+		s.pollInterval = 5 * time.Second
+	}
+	s.httpClient = &http.Client{Timeout: s.timeout}
+
+	return s
+}
+
+// webhookPayload is the JSON body posted to webhook endpoints.
+type webhookPayload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, actorID uint, req *domain.CreateWebhookRequest, clientIP string) (*domain.Webhook, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &domain.Webhook{
+		CreatedBy:   actorID,
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      uniqueList(req.Events),
+		Secret:      secret,
+		Active:      true,
+	}
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, actorID, domain.AuditActionWebhookCreated, webhook.ID, clientIP, map[string]interface{}{
+		"url":    webhook.URL,
+		"events": webhook.Events,
+	})
+	return webhook, nil
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return s.webhookRepo.List(ctx)
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, id uint) (*domain.Webhook, error) {
+	webhook, err := s.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("webhook not found")
+	}
+	return webhook, nil
+}
+
+func (s *webhookService) UpdateWebhook(ctx context.Context, actorID, id uint, req *domain.UpdateWebhookRequest, clientIP string) (*domain.Webhook, error) {
+	webhook, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Description != nil {
+		webhook.Description = *req.Description
+	}
+	if len(req.Events) > 0 {
+		webhook.Events = uniqueList(req.Events)
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, actorID, domain.AuditActionWebhookUpdated, webhook.ID, clientIP, map[string]interface{}{
+		"url":    webhook.URL,
+		"events": webhook.Events,
+		"active": webhook.Active,
+	})
+	return webhook, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, actorID, id uint, clientIP string) error {
+	webhook, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.Delete(ctx, webhook.ID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, actorID, domain.AuditActionWebhookDeleted, webhook.ID, clientIP, map[string]interface{}{
+		"url": webhook.URL,
+	})
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, webhookID uint, filter domain.WebhookDeliveryFilter) ([]domain.WebhookDelivery, int64, error) {
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.deliveryRepo.List(ctx, webhookID, filter)
+}
+
+func (s *webhookService) GetDelivery(ctx context.Context, webhookID, deliveryID uint) (*domain.WebhookDelivery, error) {
+	delivery, err := s.deliveryRepo.FindByID(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, errors.New("delivery not found")
+	}
+	return delivery, nil
+}
+
+// Redeliver queues the event again as a new delivery with a fresh set of
+// attempts. The event ID is kept so receivers can deduplicate.
+func (s *webhookService) Redeliver(ctx context.Context, actorID, webhookID, deliveryID uint, clientIP string) (*domain.WebhookDelivery, error) {
+	original, err := s.GetDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status == domain.DeliveryStatusPending {
+		return nil, errors.New("delivery is still pending")
+	}
+
+	deliveries := []domain.WebhookDelivery{{
+		WebhookID:     original.WebhookID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        domain.DeliveryStatusPending,
+		NextAttemptAt: time.Now(),
+	}}
+	if err := s.deliveryRepo.Create(ctx, deliveries); err != nil {
+		return nil, err
+	}
+	s.notify()
+
+	s.audit(ctx, actorID, domain.AuditActionWebhookRedelivered, webhookID, clientIP, map[string]interface{}{
+		"delivery_id":     original.ID,
+		"new_delivery_id": deliveries[0].ID,
+		"event_id":        original.EventID,
+	})
+	return &deliveries[0], nil
+}
+
+func (s *webhookService) HandleEvent(ctx context.Context, event eventbus.Event) {
+	webhooks, err := s.webhookRepo.FindActive(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load webhooks", "event_type", event.Type, "event_id", event.ID, "error", err)
+		return
+	}
+
+	var payload []byte
+	var deliveries []domain.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.Events.Has(event.Type) || !webhookReceives(&webhook, event) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(webhookPayload{
+				ID:        event.ID,
+				Type:      event.Type,
+				CreatedAt: event.OccurredAt,
+				Data:      event.Payload,
+			})
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to encode webhook payload", "event_type", event.Type, "event_id", event.ID, "error", err)
+				return
+			}
+		}
+
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        domain.DeliveryStatusPending,
+			NextAttemptAt: event.OccurredAt,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := s.deliveryRepo.Create(ctx, deliveries); err != nil {
+		slog.ErrorContext(ctx, "Failed to queue webhook deliveries", "event_type", event.Type, "event_id", event.ID, "error", err)
+		return
+	}
+	s.notify()
+}
+
+// webhookReceives reports whether a webhook limited to one user should get
+// the event.
+func webhookReceives(webhook *domain.Webhook, event eventbus.Event) bool {
+	if webhook.UserID == nil {
+		return true
+	}
+	scoped, ok := event.Payload.(domain.UserScopedEvent)
+	return ok && scoped.Involves(*webhook.UserID)
+}
+
+func (s *webhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *webhookService) audit(ctx context.Context, actorID uint, action string, webhookID uint, clientIP string, metadata map[string]interface{}) {
+	recordAudit(ctx, s.auditRepo, &domain.AuditLog{
+		ActorID:    &actorID,
+		Action:     action,
+		TargetType: "webhook",
+		TargetID:   strconv.FormatUint(uint64(webhookID), 10),
+		IP:         clientIP,
+	}, metadata)
+}
+
+func newWebhookSecret() (string, error) {
+	secret, err := utils.RandomString(32)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + secret, nil
+}
+
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook URL must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func uniqueList(values []string) domain.StringList {
+	var list domain.StringList
+	for _, value := range values {
+		if !list.Has(value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	TokenPurposeUnlock        = "unlock"
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeUnsubscribe   = "unsubscribe"
+)
+
+// ActionToken is a short-lived token sent by email to let a user perform a
+// single action (unlock an account, reset a password...) without logging in.
+type ActionToken struct {
+	Purpose string `json:"purpose"`
+	UserID  uint   `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// actionTokenKey derives a per-purpose key so action tokens cannot be used as
+// access tokens or for a different purpose.
+func actionTokenKey(purpose, secret string) []byte {
+	return []byte("action:" + purpose + ":" + secret)
+}
+
+func GenerateActionToken(purpose string, userID uint, secret string, ttl time.Duration) (string, error) {
+	claims := ActionToken{
+		Purpose: purpose,
+		UserID:  userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(actionTokenKey(purpose, secret))
+}
+
+func ValidateActionToken(tokenString, purpose, secret string) (uint, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ActionToken{}, func(token *jwt.Token) (interface{}, error) {
+		return actionTokenKey(purpose, secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+
+	if err != nil {
+		return 0, err
+	}
+
+	if claims, ok := token.Claims.(*ActionToken); ok && token.Valid && claims.Purpose == purpose {
+		return claims.UserID, nil
+	}
+
+	return 0, errors.New("invalid token")
+}
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP INDEX idx_role,
+    DROP COLUMN password_reset_required,
+    DROP COLUMN suspended_at,
+    DROP COLUMN role;
\ No newline at end of file
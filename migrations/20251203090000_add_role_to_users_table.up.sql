@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user' AFTER password,
+    ADD COLUMN suspended_at TIMESTAMP NULL AFTER role,
+    ADD COLUMN password_reset_required BOOLEAN DEFAULT FALSE AFTER suspended_at,
+    ADD INDEX idx_role (role);
\ No newline at end of file
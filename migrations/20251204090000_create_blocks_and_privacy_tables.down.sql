@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN last_seen_at;
+DROP TABLE IF EXISTS privacy_settings;
+DROP TABLE IF EXISTS user_blocks;
\ No newline at end of file
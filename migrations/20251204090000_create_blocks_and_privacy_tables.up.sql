@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS user_blocks (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    blocker_id BIGINT UNSIGNED NOT NULL,
+    blocked_id BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_blocker_blocked (blocker_id, blocked_id),
+    INDEX idx_blocked_id (blocked_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS privacy_settings (
+    user_id BIGINT UNSIGNED PRIMARY KEY,
+    message_permission VARCHAR(20) NOT NULL DEFAULT 'everyone',
+    last_seen_visibility VARCHAR(20) NOT NULL DEFAULT 'everyone',
+    read_receipts VARCHAR(20) NOT NULL DEFAULT 'everyone',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE users ADD COLUMN last_seen_at TIMESTAMP NULL AFTER password_reset_required;
\ No newline at end of file
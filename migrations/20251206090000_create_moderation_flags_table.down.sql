@@ -0,0 +1 @@
+DROP TABLE IF EXISTS moderation_flags;
\ No newline at end of file
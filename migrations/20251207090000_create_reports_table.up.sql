@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS reports (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    reporter_id BIGINT UNSIGNED NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id BIGINT UNSIGNED NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    details TEXT,
+    snapshot TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    assigned_to BIGINT UNSIGNED NULL,
+    resolution VARCHAR(50),
+    resolution_note TEXT,
+    resolved_by BIGINT UNSIGNED NULL,
+    resolved_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_reporter_id (reporter_id),
+    INDEX idx_target (target_type, target_id),
+    INDEX idx_status (status),
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS bot_tokens;
+DROP TABLE IF EXISTS bots;
+ALTER TABLE webhooks
+    DROP INDEX idx_user_id,
+    DROP COLUMN user_id;
+ALTER TABLE users DROP COLUMN is_bot;
\ No newline at end of file
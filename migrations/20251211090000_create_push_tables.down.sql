@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS conversation_mutes;
+DROP TABLE IF EXISTS device_tokens;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS digest_preferences (
+    user_id BIGINT UNSIGNED PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    frequency VARCHAR(20) NOT NULL DEFAULT 'daily',
+    last_sent_at TIMESTAMP NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
@@ -2,14 +2,17 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"time"
 
 	"github.com/taufiqoo/go-chat/internal/config"
 	"github.com/taufiqoo/go-chat/internal/domain"
+	"github.com/taufiqoo/go-chat/pkg/logger"
+	"github.com/taufiqoo/go-chat/pkg/metrics"
+	"github.com/taufiqoo/go-chat/pkg/tracing"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 func NewMySQLConnection(cfg *config.Config) (*gorm.DB, error) {
@@ -17,7 +20,7 @@ func NewMySQLConnection(cfg *config.Config) (*gorm.DB, error) {
 
 	if cfg.CloudSQLConnectionName != "" {
 		// Koneksi cloud run
-		log.Println("Using Cloud SQL Unix Socket connection.")
+		slog.Info("Using Cloud SQL Unix Socket connection")
 		// Format DSN: user:pass@unix(/cloudsql/PROJECT:REGION:INSTANCE)/dbname?params
 		dsn = fmt.Sprintf("%s:%s@unix(/cloudsql/%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 			cfg.DBUser,
@@ -27,7 +30,7 @@ func NewMySQLConnection(cfg *config.Config) (*gorm.DB, error) {
 		)
 	} else {
 		// Koneksi local
-		log.Println("Using standard TCP connection.")
+		slog.Info("Using standard TCP connection")
 		// Format DSN: user:pass@tcp(host:port)/dbname?params
 		dsn = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 			cfg.DBUser,
@@ -39,28 +42,68 @@ func NewMySQLConnection(cfg *config.Config) (*gorm.DB, error) {
 	}
 
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: logger.NewGormLogger(time.Duration(cfg.DBSlowQueryThreshold) * time.Millisecond),
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := metrics.RegisterGORM(db); err != nil {
+		return nil, fmt.Errorf("failed to register query metrics: %w", err)
+	}
+	if err := tracing.InstrumentGORM(db); err != nil {
+		return nil, fmt.Errorf("failed to register query tracing: %w", err)
+	}
 
-	log.Println("Database connection established")
+	slog.Info("Database connection established")
 	return db, nil
 }
 
+// Close waits for running queries to finish and closes the connection pool.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
 func AutoMigrate(db *gorm.DB) error {
+	models := []interface{}{
+		&domain.User{},
+		&domain.Message{},
+		&domain.UserIdentity{},
+		&domain.AuditLog{},
+		&domain.UserBlock{},
+		&domain.PrivacySettings{},
+		&domain.Contact{},
+		&domain.ModerationFlag{},
+		&domain.Report{},
+		&domain.Webhook{},
+		&domain.WebhookDelivery{},
+		&domain.WebhookDeliveryAttempt{},
+		&domain.Bot{},
+		&domain.BotToken{},
+		&domain.OutboxEvent{},
+		&domain.DeviceToken{},
+		&domain.ConversationMute{},
+		&domain.DigestPreferences{},
+	}
+
 	// Check apakah table sudah ada dari migration files
-	if db.Migrator().HasTable(&domain.User{}) && db.Migrator().HasTable(&domain.Message{}) {
-		log.Println("✓ Tables already exist, skipping auto migration")
+	allExist := true
+	for _, model := range models {
+		if !db.Migrator().HasTable(model) {
+			allExist = false
+			break
+		}
+	}
+	if allExist {
+		slog.Info("Tables already exist, skipping auto migration")
 		return nil
 	}
 
 	// Kalau belum ada, baru jalankan auto migrate
-	log.Println("→ Running auto migration...")
-	return db.AutoMigrate(
-		&domain.User{},
-		&domain.Message{},
-	)
+	slog.Info("Running auto migration")
+	return db.AutoMigrate(models...)
 }
@@ -0,0 +1,120 @@
+package eventbus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/taufiqoo/go-chat/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// All subscribes a handler to every event type.
+const All = "*"
+
+// Event is a domain event published inside the process.
+type Event struct {
+	ID         string
+	Type       string
+	OccurredAt time.Time
+	Payload    interface{}
+}
+
+// Handler handles an event. ctx carries the publisher's trace and request ID
+// but not its cancellation, and expires after the bus' handler timeout.
+type Handler func(ctx context.Context, event Event)
+
+// Publisher is what services depend on to emit events.
+type Publisher interface {
+	Publish(ctx context.Context, eventType string, payload interface{})
+}
+
+// Bus is an in-process publish/subscribe bus. Handlers run synchronously in
+// the publisher's goroutine, so they should only hand the event off (queue
+// it, write it to a table) and return.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	// timeout is the deadline of the context each handler gets. The context
+	// is cancelled when it passes, so handlers that respect it give up
+	// instead of holding up the publisher; the bus does not stop a handler
+	// that ignores it. Zero means no deadline.
+	timeout time.Duration
+}
+
+func New(handlerTimeout time.Duration) *Bus {
+	return &Bus{
+		handlers: make(map[string][]Handler),
+		timeout:  handlerTimeout,
+	}
+}
+
+// Subscribe registers handler for eventType, or for every event with All.
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+func (b *Bus) Publish(ctx context.Context, eventType string, payload interface{}) {
+	b.PublishEvent(ctx, NewEvent(eventType, payload))
+}
+
+// PublishEvent publishes an event that already has an ID, e.g. one that was
+// stored in the outbox first.
+func (b *Bus) PublishEvent(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[event.Type])+len(b.handlers[All]))
+	handlers = append(handlers, b.handlers[event.Type]...)
+	handlers = append(handlers, b.handlers[All]...)
+	b.mu.RUnlock()
+
+	// Events are often published once the request's work is done, and
+	// subscribers must finish handling them even if it is cancelled.
+	ctx, span := tracing.Start(context.WithoutCancel(ctx), "eventbus.publish "+event.Type)
+	span.SetAttributes(
+		attribute.String("event.type", event.Type),
+		attribute.String("event.id", event.ID),
+	)
+	defer span.End()
+
+	for _, handler := range handlers {
+		b.dispatch(ctx, handler, event)
+	}
+}
+
+// dispatch keeps a failing subscriber from breaking the publisher or the
+// other subscribers.
+func (b *Bus) dispatch(ctx context.Context, handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "Event handler panicked", "event_type", event.Type, "event_id", event.ID, "panic", r)
+		}
+	}()
+
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+	handler(ctx, event)
+}
+
+// NewEvent returns an event with a new random ID, occurring now.
+func NewEvent(eventType string, payload interface{}) Event {
+	return Event{
+		ID:         newEventID(),
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Payload:    payload,
+	}
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
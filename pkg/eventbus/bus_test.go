@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type requestIDKey struct{}
+
+func TestPublishContext(t *testing.T) {
+	bus := New(time.Second)
+
+	var errs []error
+	check := func(ctx context.Context, event Event) {
+		switch _, hasDeadline := ctx.Deadline(); {
+		case ctx.Value(requestIDKey{}) != "req-1":
+			t.Error("handler context lost the request ID")
+		case !hasDeadline:
+			t.Error("handler context has no deadline")
+		}
+		errs = append(errs, ctx.Err())
+	}
+	bus.Subscribe("test", check)
+	bus.Subscribe(All, func(ctx context.Context, event Event) {
+		panic("failing subscriber")
+	})
+	bus.Subscribe(All, check)
+
+	// Published after the request has ended.
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requestIDKey{}, "req-1"))
+	cancel()
+	bus.Publish(ctx, "test", nil)
+
+	if len(errs) != 2 {
+		t.Fatalf("handlers called = %d, want 2", len(errs))
+	}
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("handler context error = %v, want nil", err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// Counter is a subscriber that counts published events by type.
+type Counter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func NewCounter() *Counter {
+	return &Counter{counts: make(map[string]int64)}
+}
+
+func (c *Counter) Handle(ctx context.Context, event Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[event.Type]++
+}
+
+// Counts returns the number of events of each type since the server started.
+func (c *Counter) Counts() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[string]int64, len(c.counts))
+	for eventType, n := range c.counts {
+		counts[eventType] = n
+	}
+	return counts
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"context"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	userIDKey
+)
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the ID of the request ctx belongs to, if any.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func WithUserID(ctx context.Context, id uint) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// UserID returns the authenticated user ctx belongs to, if any.
+func UserID(ctx context.Context) uint {
+	if ctx == nil {
+		return 0
+	}
+	id, _ := ctx.Value(userIDKey).(uint)
+	return id
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger writes GORM's logs through slog. Failed and slow queries are
+// logged as errors and warnings, every other statement only at debug level.
+// Statements are logged with placeholders, never with their values.
+type gormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger returns a GORM logger. A zero slowThreshold disables slow
+// query warnings.
+func NewGormLogger(slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{level: gormlogger.Info, slowThreshold: slowThreshold}
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "Query failed", "error", err, "sql", sql, "rows", rows, "duration_ms", Milliseconds(elapsed))
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "Slow query", "sql", sql, "rows", rows, "duration_ms", Milliseconds(elapsed))
+	case l.level >= gormlogger.Info && slog.Default().Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		slog.DebugContext(ctx, "Query", "sql", sql, "rows", rows, "duration_ms", Milliseconds(elapsed))
+	}
+}
+
+// ParamsFilter drops the query arguments, which can hold password hashes
+// and tokens.
+func (l *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup installs the configured logger as the default. slog also routes the
+// standard log package through it, which third-party libraries still use.
+func Setup(cfg *config.Config) *slog.Logger {
+	logger := New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// New returns a logger writing to w. format is "json" or "text", level one
+// of "debug", "info", "warn" or "error". Sensitive attributes are redacted
+// and request and user IDs are taken from the context.
+func New(w io.Writer, level, format string) *slog.Logger {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		lvl = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       lvl,
+		ReplaceAttr: redactAttr,
+	}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, "text") {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// contextHandler adds the request and user IDs stored in the context, and
+// the current trace, to every record logged with it.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if id := UserID(ctx); id != 0 {
+		record.AddAttrs(slog.Uint64("user_id", uint64(id)))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Milliseconds formats a duration for the duration_ms attribute.
+func Milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var sensitiveKeys = []string{
+	"password",
+	"token",
+	"secret",
+	"authorization",
+	"cookie",
+	"api_key",
+	"private_key",
+}
+
+// IsSensitive reports whether values under key must not be logged.
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if IsSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+// RedactURL hides the values of query parameters carrying credentials, like
+// the token of WebSocket, unlock and unsubscribe links or the OIDC code.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+
+	query := u.Query()
+	changed := false
+	for key := range query {
+		if IsSensitive(key) || key == "code" || key == "state" {
+			query.Set(key, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return raw
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,122 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+)
+
+type Mail struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+	// Headers are added to the standard ones, e.g. List-Unsubscribe.
+	Headers map[string]string
+}
+
+type Mailer interface {
+	Send(mail *Mail) error
+}
+
+// NewMailer returns an SMTP mailer, or a mailer that only logs when no SMTP
+// host is configured.
+func NewMailer(cfg *config.Config) Mailer {
+	if cfg.SMTPHost == "" {
+		slog.Warn("SMTP not configured - emails will only be logged")
+		return &logMailer{}
+	}
+
+	return &smtpMailer{
+		addr:     fmt.Sprintf("%s:%s", cfg.SMTPHost, cfg.SMTPPort),
+		host:     cfg.SMTPHost,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.SMTPFrom,
+	}
+}
+
+type smtpMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func (m *smtpMailer) Send(mail *Mail) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	body, err := buildMessage(m.from, mail)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, auth, m.from, []string{mail.To}, body)
+}
+
+func buildMessage(from string, mail *Mail) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	headers := []string{
+		"From: " + from,
+		"To: " + mail.To,
+		"Subject: " + mail.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + writer.Boundary(),
+	}
+	names := make([]string, 0, len(mail.Headers))
+	for name := range mail.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		headers = append(headers, name+": "+mail.Headers[name])
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n"
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", mail.TextBody},
+		{"text/html; charset=UTF-8", mail.HTMLBody},
+	}
+
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+		w, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return append([]byte(body), buf.Bytes()...), nil
+}
+
+type logMailer struct{}
+
+func (m *logMailer) Send(mail *Mail) error {
+	slog.Info("Mail", "to", mail.To, "subject", mail.Subject, "body", mail.TextBody)
+	return nil
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+const gormStartKey = "metrics:start"
+
+var (
+	dbDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	dbErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_errors_total",
+		Help:      "Failed database queries by operation. Missing records are not counted.",
+	}, []string{"operation"})
+)
+
+// RegisterGORM times every query run through db.
+func RegisterGORM(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", startQuery),
+		cb.Create().After("gorm:create").Register("metrics:after_create", finishQuery("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", startQuery),
+		cb.Query().After("gorm:query").Register("metrics:after_query", finishQuery("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", startQuery),
+		cb.Update().After("gorm:update").Register("metrics:after_update", finishQuery("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", startQuery),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", finishQuery("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", startQuery),
+		cb.Row().After("gorm:row").Register("metrics:after_row", finishQuery("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", startQuery),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", finishQuery("raw")),
+	)
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func finishQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		dbDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbErrors.WithLabelValues(operation).Inc()
+		}
+	}
+}
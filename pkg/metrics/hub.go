@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/taufiqoo/go-chat/internal/domain"
+)
+
+// HubStats is implemented by the WebSocket hub.
+type HubStats interface {
+	ConnectedClients() int
+	ConnectedUsers() int
+	// ConnectionsPerUser returns the number of live connections of each
+	// connected user.
+	ConnectionsPerUser() []int
+	DeliveryStats() domain.DeliveryStats
+}
+
+var connectionsPerUserBuckets = []float64{1, 2, 3, 5, 10}
+
+// hubCollector reads the hub's state on every scrape instead of keeping a
+// second copy of its counters.
+type hubCollector struct {
+	hub HubStats
+
+	connections        *prometheus.Desc
+	users              *prometheus.Desc
+	connectionsPerUser *prometheus.Desc
+	delivered          *prometheus.Desc
+	dropped            *prometheus.Desc
+	queued             *prometheus.Desc
+	overflows          *prometheus.Desc
+	slowDisconnects    *prometheus.Desc
+}
+
+// RegisterHub exposes the hub's connection and delivery statistics.
+func RegisterHub(hub HubStats) {
+	prometheus.MustRegister(&hubCollector{
+		hub: hub,
+		connections: prometheus.NewDesc(namespace+"_ws_connections",
+			"Live WebSocket, SSE, long-poll and gRPC connections.", nil, nil),
+		users: prometheus.NewDesc(namespace+"_ws_connected_users",
+			"Users with at least one live connection.", nil, nil),
+		connectionsPerUser: prometheus.NewDesc(namespace+"_ws_connections_per_user",
+			"Distribution of live connections (devices) per connected user.", nil, nil),
+		delivered: prometheus.NewDesc(namespace+"_ws_frames_delivered_total",
+			"Frames queued on a connection's send buffer.", nil, nil),
+		dropped: prometheus.NewDesc(namespace+"_ws_frames_dropped_total",
+			"Frames discarded because a connection could not keep up.", nil, nil),
+		queued: prometheus.NewDesc(namespace+"_ws_frames_queued_total",
+			"Frames stored in the pending queue for a slow connection.", nil, nil),
+		overflows: prometheus.NewDesc(namespace+"_ws_send_buffer_overflows_total",
+			"Times a frame did not fit in a connection's send buffer.", nil, nil),
+		slowDisconnects: prometheus.NewDesc(namespace+"_ws_slow_consumer_disconnects_total",
+			"Connections closed for being too slow.", nil, nil),
+	})
+}
+
+func (c *hubCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.users
+	ch <- c.connectionsPerUser
+	ch <- c.delivered
+	ch <- c.dropped
+	ch <- c.queued
+	ch <- c.overflows
+	ch <- c.slowDisconnects
+}
+
+func (c *hubCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(c.hub.ConnectedClients()))
+	ch <- prometheus.MustNewConstMetric(c.users, prometheus.GaugeValue, float64(c.hub.ConnectedUsers()))
+
+	counts := c.hub.ConnectionsPerUser()
+	buckets := make(map[float64]uint64, len(connectionsPerUserBuckets))
+	var sum float64
+	for _, n := range counts {
+		sum += float64(n)
+		for _, bound := range connectionsPerUserBuckets {
+			if float64(n) <= bound {
+				buckets[bound]++
+			}
+		}
+	}
+	ch <- prometheus.MustNewConstHistogram(c.connectionsPerUser, uint64(len(counts)), sum, buckets)
+
+	stats := c.hub.DeliveryStats()
+	ch <- prometheus.MustNewConstMetric(c.delivered, prometheus.CounterValue, float64(stats.DeliveredFrames))
+	ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(stats.DroppedFrames))
+	ch <- prometheus.MustNewConstMetric(c.queued, prometheus.CounterValue, float64(stats.QueuedFrames))
+	ch <- prometheus.MustNewConstMetric(c.overflows, prometheus.CounterValue, float64(stats.BufferOverflows))
+	ch <- prometheus.MustNewConstMetric(c.slowDisconnects, prometheus.CounterValue, float64(stats.SlowConsumerDisconnects))
+}
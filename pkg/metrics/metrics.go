@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/taufiqoo/go-chat/pkg/eventbus"
+)
+
+const namespace = "chat"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	messagesSent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_sent_total",
+		Help:      "Chat messages accepted for delivery.",
+	})
+
+	rateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by a rate limit policy.",
+	}, []string{"policy"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveHTTPRequest records a finished request. route is the matched route
+// pattern, so paths with IDs do not create a series each.
+func ObserveHTTPRequest(method, route string, status int, latency time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(latency.Seconds())
+}
+
+// CountMessage is an event bus subscriber for message.created.
+func CountMessage(context.Context, eventbus.Event) {
+	messagesSent.Inc()
+}
+
+// RateLimited records a request rejected by the named policy.
+func RateLimited(policy string) {
+	rateLimitRejections.WithLabelValues(policy).Inc()
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+var redisErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "redis_errors_total",
+	Help:      "Failed Redis commands by command name. Missing keys are not counted.",
+}, []string{"command"})
+
+// RedisHook counts failed Redis commands. Add it with client.AddHook.
+type RedisHook struct{}
+
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			redisErrors.WithLabelValues("dial").Inc()
+		}
+		return conn, err
+	}
+}
+
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if isRedisError(err) {
+			redisErrors.WithLabelValues(cmd.Name()).Inc()
+		}
+		return err
+	}
+}
+
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		for _, cmd := range cmds {
+			if isRedisError(cmd.Err()) {
+				redisErrors.WithLabelValues(cmd.Name()).Inc()
+			}
+		}
+		return err
+	}
+}
+
+// isRedisError leaves out missing keys and the NOSCRIPT reply that makes
+// Script.Run fall back from EVALSHA to EVAL.
+func isRedisError(err error) bool {
+	return err != nil && !errors.Is(err, redis.Nil) && !redis.HasErrorPrefix(err, "NOSCRIPT")
+}
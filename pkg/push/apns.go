@@ -0,0 +1,173 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProduction = "https://api.push.apple.com"
+	apnsSandbox    = "https://api.sandbox.push.apple.com"
+	// Apple rejects provider tokens older than an hour and throttles
+	// clients that refresh them more often than every 20 minutes.
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// apnsProvider sends over HTTP/2 with token-based (.p8 key) authentication.
+type apnsProvider struct {
+	host   string
+	keyID  string
+	teamID string
+	topic  string
+	key    *ecdsa.PrivateKey
+	client *http.Client
+
+	mu       sync.Mutex
+	signed   string
+	issuedAt time.Time
+}
+
+// NewAPNs reads the .p8 signing key created in the Apple developer account.
+// topic is the app's bundle ID.
+func NewAPNs(keyFile, keyID, teamID, topic string, sandbox bool) (PushProvider, error) {
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, errors.New("APNS_KEY_ID, APNS_TEAM_ID and APNS_TOPIC are required")
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+
+	host := apnsProduction
+	if sandbox {
+		host = apnsSandbox
+	}
+
+	return &apnsProvider{
+		host:   host,
+		keyID:  keyID,
+		teamID: teamID,
+		topic:  topic,
+		key:    key,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{ForceAttemptHTTP2: true},
+		},
+	}, nil
+}
+
+type apnsAPS struct {
+	Alert    apnsAlert `json:"alert"`
+	Badge    int       `json:"badge,omitempty"`
+	Sound    string    `json:"sound"`
+	ThreadID string    `json:"thread-id,omitempty"`
+}
+
+type apnsAlert struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+func (p *apnsProvider) Send(ctx context.Context, notification *Notification) error {
+	token, err := p.token()
+	if err != nil {
+		return err
+	}
+
+	// Custom data goes next to "aps" at the top level.
+	payload := map[string]interface{}{}
+	for key, value := range notification.Data {
+		payload[key] = value
+	}
+	payload["aps"] = apnsAPS{
+		Alert:    apnsAlert{Title: notification.Title, Body: notification.Body},
+		Badge:    notification.Badge,
+		Sound:    "default",
+		ThreadID: notification.CollapseKey,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.host+"/3/device/"+notification.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+token)
+	req.Header.Set("apns-topic", p.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	if notification.CollapseKey != "" && len(notification.CollapseKey) <= 64 {
+		req.Header.Set("apns-collapse-id", notification.CollapseKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apnsErr)
+	switch apnsErr.Reason {
+	case "BadDeviceToken", "Unregistered", "DeviceTokenNotForTopic":
+		return ErrInvalidToken
+	case "ExpiredProviderToken", "InvalidProviderToken":
+		p.resetToken()
+	}
+	return fmt.Errorf("apns: status %d: %s", resp.StatusCode, apnsErr.Reason)
+}
+
+// token returns the provider JWT, signing a new one when it gets old.
+func (p *apnsProvider) token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.signed != "" && time.Since(p.issuedAt) < apnsTokenLifetime {
+		return p.signed, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.keyID
+
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+	p.signed = signed
+	p.issuedAt = now
+	return p.signed, nil
+}
+
+func (p *apnsProvider) resetToken() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.signed = ""
+}
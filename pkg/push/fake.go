@@ -0,0 +1,43 @@
+package push
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake records notifications instead of sending them. Tokens marked invalid
+// fail with ErrInvalidToken.
+type Fake struct {
+	mu      sync.Mutex
+	sent    []Notification
+	invalid map[string]bool
+}
+
+func NewFake() *Fake {
+	return &Fake{invalid: make(map[string]bool)}
+}
+
+func (f *Fake) Send(ctx context.Context, notification *Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.invalid[notification.Token] {
+		return ErrInvalidToken
+	}
+	f.sent = append(f.sent, *notification)
+	return nil
+}
+
+// Invalidate makes sends to token fail as if the app was uninstalled.
+func (f *Fake) Invalidate(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.invalid[token] = true
+}
+
+// Sent returns the notifications sent so far.
+func (f *Fake) Sent() []Notification {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Notification(nil), f.sent...)
+}
@@ -0,0 +1,227 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// fcmProvider sends through the FCM HTTP v1 API, authenticated with an
+// OAuth token obtained for a service account.
+type fcmProvider struct {
+	endpoint    string
+	clientEmail string
+	tokenURI    string
+	key         *rsa.PrivateKey
+	client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// NewFCM reads the service account JSON downloaded from the Firebase console.
+func NewFCM(projectID, credentialsFile string) (PushProvider, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var account serviceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("invalid service account file: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account key: %w", err)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &fcmProvider{
+		endpoint:    fmt.Sprintf(fcmEndpoint, projectID),
+		clientEmail: account.ClientEmail,
+		tokenURI:    account.TokenURI,
+		key:         key,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type fcmMessage struct {
+	Message fcmMessageBody `json:"message"`
+}
+
+type fcmMessageBody struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *fcmAndroid       `json:"android,omitempty"`
+	Webpush      *fcmWebpush       `json:"webpush,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmAndroid struct {
+	CollapseKey  string                 `json:"collapse_key,omitempty"`
+	Notification fcmAndroidNotification `json:"notification"`
+}
+
+type fcmAndroidNotification struct {
+	Tag               string `json:"tag,omitempty"`
+	NotificationCount int    `json:"notification_count,omitempty"`
+}
+
+type fcmWebpush struct {
+	Headers map[string]string `json:"headers"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func (p *fcmProvider) Send(ctx context.Context, notification *Notification) error {
+	accessToken, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	message := fcmMessageBody{
+		Token:        notification.Token,
+		Notification: fcmNotification{Title: notification.Title, Body: notification.Body},
+		Data:         notification.Data,
+	}
+	if notification.Platform == PlatformWeb {
+		if notification.CollapseKey != "" {
+			message.Webpush = &fcmWebpush{Headers: map[string]string{"Topic": notification.CollapseKey}}
+		}
+	} else {
+		message.Android = &fcmAndroid{
+			CollapseKey: notification.CollapseKey,
+			Notification: fcmAndroidNotification{
+				Tag:               notification.CollapseKey,
+				NotificationCount: notification.Badge,
+			},
+		}
+	}
+
+	body, err := json.Marshal(fcmMessage{Message: message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var fcmErr fcmErrorResponse
+	json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&fcmErr)
+	for _, detail := range fcmErr.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return ErrInvalidToken
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("fcm: status %d: %s %s", resp.StatusCode, fcmErr.Error.Status, fcmErr.Error.Message)
+}
+
+// token returns a cached OAuth access token, requesting a new one shortly
+// before it expires.
+func (p *fcmProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.clientEmail,
+		"scope": fcmScope,
+		"aud":   p.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm: failed to get access token (status %d)", resp.StatusCode)
+	}
+	if result.AccessToken == "" {
+		return "", errors.New("fcm: empty access token")
+	}
+
+	p.accessToken = result.AccessToken
+	p.expiresAt = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return p.accessToken, nil
+}
@@ -0,0 +1,86 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/taufiqoo/go-chat/internal/config"
+)
+
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+)
+
+// ErrInvalidToken is returned when the push service no longer accepts the
+// device token, e.g. because the app was uninstalled. The token should be
+// removed.
+var ErrInvalidToken = errors.New("push: invalid or unregistered device token")
+
+type Notification struct {
+	Token    string
+	Platform string
+	Title    string
+	Body     string
+	// CollapseKey makes the device replace an earlier notification with the
+	// same key instead of showing another one.
+	CollapseKey string
+	Badge       int
+	Data        map[string]string
+}
+
+type PushProvider interface {
+	Send(ctx context.Context, notification *Notification) error
+}
+
+// NewFromConfig returns a provider that sends Android and web notifications
+// through FCM and iOS notifications through APNs. Platforms without
+// credentials only log their notifications.
+func NewFromConfig(cfg *config.Config) PushProvider {
+	var fcm, apns PushProvider = &logProvider{}, &logProvider{}
+
+	if cfg.FCMProjectID != "" && cfg.FCMCredentialsFile != "" {
+		provider, err := NewFCM(cfg.FCMProjectID, cfg.FCMCredentialsFile)
+		if err != nil {
+			slog.Warn("FCM disabled", "error", err)
+		} else {
+			fcm = provider
+		}
+	} else {
+		slog.Warn("FCM not configured - Android and web push notifications will only be logged")
+	}
+
+	if cfg.APNSKeyFile != "" {
+		provider, err := NewAPNs(cfg.APNSKeyFile, cfg.APNSKeyID, cfg.APNSTeamID, cfg.APNSTopic, cfg.APNSSandbox)
+		if err != nil {
+			slog.Warn("APNs disabled", "error", err)
+		} else {
+			apns = provider
+		}
+	} else {
+		slog.Warn("APNs not configured - iOS push notifications will only be logged")
+	}
+
+	return &platformProvider{fcm: fcm, apns: apns}
+}
+
+type platformProvider struct {
+	fcm  PushProvider
+	apns PushProvider
+}
+
+func (p *platformProvider) Send(ctx context.Context, notification *Notification) error {
+	if notification.Platform == PlatformIOS {
+		return p.apns.Send(ctx, notification)
+	}
+	return p.fcm.Send(ctx, notification)
+}
+
+type logProvider struct{}
+
+func (p *logProvider) Send(ctx context.Context, notification *Notification) error {
+	slog.InfoContext(ctx, "Push notification", "platform", notification.Platform, "title", notification.Title, "body", notification.Body)
+	return nil
+}
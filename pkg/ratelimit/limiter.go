@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Algorithm string
+
+const (
+	// TokenBucket allows bursts of up to Burst requests, refilled at Rate per Per.
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow allows at most Rate requests in any Per long window. It
+	// keeps a timestamp per request, so it suits low limits.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// Rule describes a limit. The algorithm defaults to TokenBucket.
+type Rule struct {
+	Algorithm Algorithm
+	Rate      int
+	Per       time.Duration
+	Burst     int
+}
+
+// Enabled reports whether the rule limits anything. A zero rate disables it.
+func (r Rule) Enabled() bool {
+	return r.Rate > 0 && r.Per > 0
+}
+
+// Limit is the number of requests that can be made at once.
+func (r Rule) Limit() int {
+	if r.Algorithm != SlidingWindow && r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Rate
+}
+
+func (r Rule) algorithm() Algorithm {
+	if r.Algorithm == "" {
+		return TokenBucket
+	}
+	return r.Algorithm
+}
+
+// refillInterval is the time needed to earn one token.
+func (r Rule) refillInterval() time.Duration {
+	return r.Per / time.Duration(r.Rate)
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is set when the request was denied.
+	RetryAfter time.Duration
+	// ResetAfter is the time until the full limit is available again.
+	ResetAfter time.Duration
+}
+
+// Limiter counts one request against the limit identified by key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (*Result, error)
+}
+
+// New returns a Redis backed limiter, or an in-memory one when Redis is not
+// configured.
+func New(redisClient *redis.Client) Limiter {
+	if redisClient == nil {
+		return NewMemoryLimiter()
+	}
+	return NewRedisLimiter(redisClient)
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memoryCleanupInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// log holds request times for the sliding window algorithm.
+	log []time.Time
+	// expires is when the entry no longer carries any state.
+	expires time.Time
+}
+
+// MemoryLimiter keeps limits in process. It is used when Redis is not
+// available and is only accurate for a single instance.
+type MemoryLimiter struct {
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:     make(map[string]*bucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, rule Rule) (*Result, error) {
+	if !rule.Enabled() {
+		return &Result{Allowed: true}, nil
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cleanup(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Limit()), updated: now}
+		l.buckets[key] = b
+	}
+
+	if rule.Algorithm == SlidingWindow {
+		return l.slidingWindow(b, rule, now), nil
+	}
+	return l.tokenBucket(b, rule, now), nil
+}
+
+func (l *MemoryLimiter) tokenBucket(b *bucket, rule Rule, now time.Time) *Result {
+	capacity := float64(rule.Limit())
+	interval := rule.refillInterval()
+
+	b.tokens += float64(now.Sub(b.updated)) / float64(interval)
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.updated = now
+
+	result := &Result{Limit: rule.Limit()}
+	if b.tokens < 1 {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(interval))
+	} else {
+		b.tokens--
+		result.Allowed = true
+	}
+
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = time.Duration((capacity - b.tokens) * float64(interval))
+	b.expires = now.Add(result.ResetAfter)
+	return result
+}
+
+func (l *MemoryLimiter) slidingWindow(b *bucket, rule Rule, now time.Time) *Result {
+	windowStart := now.Add(-rule.Per)
+
+	kept := b.log[:0]
+	for _, t := range b.log {
+		if t.After(windowStart) {
+			kept = append(kept, t)
+		}
+	}
+	b.log = kept
+
+	result := &Result{Limit: rule.Rate}
+	if len(b.log) >= rule.Rate {
+		result.RetryAfter = b.log[0].Add(rule.Per).Sub(now)
+	} else {
+		b.log = append(b.log, now)
+		result.Allowed = true
+	}
+
+	result.Remaining = rule.Rate - len(b.log)
+	result.ResetAfter = b.log[len(b.log)-1].Add(rule.Per).Sub(now)
+	b.expires = now.Add(result.ResetAfter)
+	return result
+}
+
+func (l *MemoryLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < memoryCleanupInterval {
+		return
+	}
+	l.lastCleanup = now
+
+	for key, b := range l.buckets {
+		if now.After(b.expires) {
+			delete(l.buckets, key)
+		}
+	}
+}
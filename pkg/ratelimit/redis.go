@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "ratelimit:"
+
+// Both scripts return {allowed, remaining, retry_after_ms, reset_after_ms}.
+
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil then
+	tokens = capacity
+	updated = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - updated) / interval)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * interval)
+end
+
+local reset = math.ceil((capacity - tokens) * interval)
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], math.max(1, reset))
+
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+
+local allowed = 0
+local retry = 0
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+else
+	local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+	retry = tonumber(oldest[2]) + window - now
+end
+
+local newest = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+local reset = tonumber(newest[2]) + window - now
+redis.call("PEXPIRE", KEYS[1], window)
+
+return {allowed, limit - count, retry, reset}
+`)
+
+// RedisLimiter shares limits between instances. If a Redis command fails it
+// falls back to an in-memory limiter so limiting is never switched off.
+type RedisLimiter struct {
+	client *redis.Client
+	memory *MemoryLimiter
+}
+
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		memory: NewMemoryLimiter(),
+	}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	if !rule.Enabled() {
+		return &Result{Allowed: true}, nil
+	}
+
+	now := time.Now().UnixMilli()
+	redisKey := []string{redisKeyPrefix + string(rule.algorithm()) + ":" + key}
+
+	var cmd *redis.Cmd
+	if rule.Algorithm == SlidingWindow {
+		// Requests in the same millisecond need distinct set members.
+		member := strconv.FormatInt(now, 10) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+		cmd = slidingWindowScript.Run(ctx, l.client, redisKey, rule.Rate, rule.Per.Milliseconds(), now, member)
+	} else {
+		interval := float64(rule.refillInterval()) / float64(time.Millisecond)
+		cmd = tokenBucketScript.Run(ctx, l.client, redisKey, rule.Limit(), interval, now)
+	}
+
+	values, err := cmd.Int64Slice()
+	if err != nil || len(values) != 4 {
+		slog.WarnContext(ctx, "Rate limiter falling back to memory", "error", err)
+		return l.memory.Allow(ctx, key, rule)
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      rule.Limit(),
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
@@ -3,10 +3,12 @@ package redis
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/taufiqoo/go-chat/internal/config"
+	"github.com/taufiqoo/go-chat/pkg/metrics"
+	"github.com/taufiqoo/go-chat/pkg/tracing"
 )
 
 func NewRedisClient(cfg *config.Config) *redis.Client {
@@ -15,13 +17,17 @@ func NewRedisClient(cfg *config.Config) *redis.Client {
 		Password: cfg.RedisPassword,
 		DB:       cfg.RedisDB,
 	})
+	client.AddHook(metrics.RedisHook{})
+	if err := tracing.InstrumentRedis(client); err != nil {
+		slog.Warn("Redis tracing disabled", "error", err)
+	}
 
 	ctx := context.Background()
 	if err := client.Ping(ctx).Err(); err != nil {
-		log.Printf("Warning: Redis connection failed: %v", err)
+		slog.Warn("Redis connection failed", "error", err)
 		return nil
 	}
 
-	log.Println("Redis connected successfully")
+	slog.Info("Redis connected successfully")
 	return client
 }
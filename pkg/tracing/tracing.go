@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/taufiqoo/go-chat/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	instrumentationName = "github.com/taufiqoo/go-chat"
+)
+
+// Setup installs the global tracer provider and W3C trace context
+// propagation. The returned function flushes buffered spans and must be
+// called on shutdown. With the none exporter spans are not recorded.
+func Setup(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.TracingExporter {
+	case ExporterOTLP:
+		// Without an endpoint the exporter reads the standard
+		// OTEL_EXPORTER_OTLP_* variables, defaulting to localhost:4318.
+		var opts []otlptracehttp.Option
+		if cfg.TracingOTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.TracingOTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.TracingExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.TracingExporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.TracingServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	slog.Info("Tracing enabled", "exporter", cfg.TracingExporter, "sample_ratio", cfg.TracingSampleRatio)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span with the application's tracer.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// RecordError marks the span as failed.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// InstrumentGORM creates a span for every query run with a context. Query
+// arguments are left out, like in the logs.
+func InstrumentGORM(db *gorm.DB) error {
+	return db.Use(gormtracing.NewPlugin(
+		gormtracing.WithoutQueryVariables(),
+		gormtracing.WithoutMetrics(),
+	))
+}
+
+// InstrumentRedis creates a span for every command. Command arguments are
+// left out, as they hold tokens and queued frames.
+func InstrumentRedis(client *redis.Client) error {
+	return redisotel.InstrumentTracing(client, redisotel.WithDBStatement(false))
+}